@@ -0,0 +1,86 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// PathMode controls how the caller file is printed
+type PathMode int
+
+// Path modes
+const (
+	// ShortPath prints the file name and the package name of the function
+	ShortPath PathMode = iota
+	// FullPath prints the absolute file path and the full package path of
+	// the function
+	FullPath
+)
+
+// Caller is the source location an entry was logged from
+type Caller struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"func"`
+}
+
+// helpers holds the names of functions marked with Helper
+var helpers sync.Map
+
+// Helper marks the calling function as a logging helper. Helper functions
+// are skipped when finding the caller, so the location of the code that
+// called the helper is reported instead. Like testing.T.Helper it applies to
+// the function, so it affects every logger.
+func (l *Logger) Helper() {
+	var pc [1]uintptr
+	if runtime.Callers(2, pc[:]) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pc[:]).Next()
+	helpers.Store(frame.Function, struct{}{})
+}
+
+// caller returns the first non helper frame skip frames above its caller
+func caller(skip int) Caller {
+	var pcs [32]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if _, ok := helpers.Load(frame.Function); !ok || !more {
+			return Caller{File: frame.File, Line: frame.Line, Function: frame.Function}
+		}
+	}
+}
+
+// Package returns the import path of the package of the function
+func (c Caller) Package() string {
+	slash := strings.LastIndex(c.Function, "/")
+	dot := strings.Index(c.Function[slash+1:], ".")
+	if dot < 0 {
+		return c.Function
+	}
+	return c.Function[:slash+1+dot]
+}
+
+// ShortFunction returns the function name qualified by the package name
+// only, e.g. log.(*Logger).Info
+func (c Caller) ShortFunction() string {
+	return c.Function[strings.LastIndex(c.Function, "/")+1:]
+}
+
+func (l *Logger) formatCaller(c Caller) string {
+	file := filepath.Base(c.File)
+	function := c.ShortFunction()
+	if l.CallerPath == FullPath {
+		file = c.File
+		function = c.Function
+	}
+	if l.CallerFunc {
+		return fmt.Sprintf("%v:%v %v", file, c.Line, function)
+	}
+	return fmt.Sprintf("%v:%v", file, c.Line)
+}
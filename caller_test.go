@@ -0,0 +1,93 @@
+package log_test
+
+import (
+	"bytes"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func newCallerLogger() *logger.Logger {
+	log := &logger.Logger{Level: 4, Function: true}
+	log.SetOutput(&bytes.Buffer{})
+	return log
+}
+
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l
+}
+
+func at(l int) string {
+	return "[caller_test.go:" + strconv.Itoa(l) + "]"
+}
+
+func TestCaller(t *testing.T) {
+	log := newCallerLogger()
+	s, l := log.Info("info"), line()
+	if !strings.Contains(s, at(l)+" info") {
+		t.Errorf("Test expected caller_test.go:%v actual: %v", l, s)
+	}
+}
+
+func TestCallerFunc(t *testing.T) {
+	log := newCallerLogger()
+	log.CallerFunc = true
+	s := log.Info("info")
+	if !strings.Contains(s, " simple-logger_test.TestCallerFunc] info") {
+		t.Errorf("Test expected short function actual: %v", s)
+	}
+
+	log.CallerPath = logger.FullPath
+	s = log.Info("info")
+	re := regexp.MustCompile(`\[/\S+/caller_test.go:\d+ github.com/casonadams/simple-logger_test.TestCallerFunc\] info$`)
+	if !re.MatchString(s) {
+		t.Errorf("Test expected: %v actual: %v", re, s)
+	}
+}
+
+func wrapper(log *logger.Logger, msg string) (string, int) {
+	return log.Info(msg), line()
+}
+
+func helper(log *logger.Logger, msg string) string {
+	log.Helper()
+	return log.Info(msg)
+}
+
+func TestCallerSkip(t *testing.T) {
+	log := newCallerLogger()
+	s, l := wrapper(log, "info")
+	if !strings.Contains(s, at(l)) {
+		t.Errorf("Test expected caller_test.go:%v actual: %v", l, s)
+	}
+
+	log.CallerSkip = 1
+	s, _ = wrapper(log, "info")
+	l = line() - 1
+	if !strings.Contains(s, at(l)) {
+		t.Errorf("Test expected caller_test.go:%v actual: %v", l, s)
+	}
+}
+
+func TestHelper(t *testing.T) {
+	log := newCallerLogger()
+	s, l := helper(log, "info"), line()
+	if !strings.Contains(s, at(l)) {
+		t.Errorf("Test expected caller_test.go:%v actual: %v", l, s)
+	}
+}
+
+func TestCallerPackage(t *testing.T) {
+	c := logger.Caller{Function: "github.com/casonadams/simple-logger.(*Logger).Info"}
+	if c.Package() != "github.com/casonadams/simple-logger" {
+		t.Errorf("unexpected package %v", c.Package())
+	}
+	if c.ShortFunction() != "simple-logger.(*Logger).Info" {
+		t.Errorf("unexpected function %v", c.ShortFunction())
+	}
+}
@@ -0,0 +1,71 @@
+package log
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// ColorMode controls when ANSI colors are written
+type ColorMode int
+
+// Color modes
+const (
+	// ColorAuto enables color only when the output is a terminal. NO_COLOR
+	// disables it, FORCE_COLOR and CLICOLOR_FORCE force it on.
+	ColorAuto ColorMode = iota
+	// ColorAlways enables color regardless of the output
+	ColorAlways
+	// ColorNever disables color regardless of the output
+	ColorNever
+)
+
+func (m ColorMode) enabled(w io.Writer) bool {
+	switch m {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if forced("FORCE_COLOR") || forced("CLICOLOR_FORCE") {
+		return true
+	}
+	return isTerminal(w)
+}
+
+// forced reports whether a force color env var is set to anything but off,
+// like NO_COLOR an empty value counts as unset
+func forced(key string) bool {
+	v := strings.ToLower(os.Getenv(key))
+	return v != "" && v != "0" && v != "false"
+}
+
+// colored reports whether entries are written with color. ColorAlways and
+// ColorNever apply as soon as they are set, with ColorAuto Color holds the
+// decision made for the output.
+func (l *Logger) colored() bool {
+	switch l.ColorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return l.Color
+}
+
+// isTerminal reports whether w is a character device such as a tty
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
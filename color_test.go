@@ -0,0 +1,136 @@
+package log_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestColorMode(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out logger.ColorMode
+	}{
+		{"", logger.ColorAuto},
+		{"auto", logger.ColorAuto},
+		{"AUTO", logger.ColorAuto},
+		{"true", logger.ColorAlways},
+		{"1", logger.ColorAlways},
+		{"false", logger.ColorNever},
+		{"0", logger.ColorNever},
+	}
+
+	for i, tt := range tests {
+		os.Setenv("LOG_COLOR", tt.in)
+		actual := logger.NewLogger("test")
+		if tt.out != actual.ColorMode {
+			t.Errorf("Test(%v): expected %v, actual %v", i, tt.out, actual.ColorMode)
+		}
+	}
+	os.Unsetenv("LOG_COLOR")
+}
+
+func TestColorAuto(t *testing.T) {
+	var tests = []struct {
+		noColor    string
+		forceColor string
+		cliColor   string
+		out        bool
+	}{
+		{"", "", "", false},
+		{"1", "", "", false},
+		{"", "1", "", true},
+		{"", "0", "", false},
+		{"", "", "1", true},
+		{"", "", "0", false},
+		{"1", "1", "1", false},
+	}
+
+	for i, tt := range tests {
+		setOrUnset("NO_COLOR", tt.noColor)
+		setOrUnset("FORCE_COLOR", tt.forceColor)
+		setOrUnset("CLICOLOR_FORCE", tt.cliColor)
+
+		log := &logger.Logger{Level: 4}
+		log.SetOutput(&bytes.Buffer{})
+		if tt.out != log.Color {
+			t.Errorf("Test(%v): expected %v, actual %v", i, tt.out, log.Color)
+		}
+	}
+	os.Unsetenv("NO_COLOR")
+	os.Unsetenv("FORCE_COLOR")
+	os.Unsetenv("CLICOLOR_FORCE")
+}
+
+func TestSetOutputColor(t *testing.T) {
+	os.Unsetenv("NO_COLOR")
+	os.Setenv("FORCE_COLOR", "1")
+	log := &logger.Logger{Level: 4}
+	log.SetOutput(&bytes.Buffer{})
+	if !log.Color {
+		t.Errorf("expected color with FORCE_COLOR")
+	}
+
+	os.Unsetenv("FORCE_COLOR")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	if log.Color {
+		t.Errorf("expected color to be re-evaluated on SetOutput")
+	}
+	log.Info("info")
+	if strings.Contains(buf.String(), "\033[") || !strings.HasSuffix(buf.String(), "INFO info\n") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	log.SetColorMode(logger.ColorAlways)
+	if !log.Color {
+		t.Errorf("expected color with ColorAlways")
+	}
+	log.SetColorMode(logger.ColorNever)
+	if log.Color {
+		t.Errorf("expected no color with ColorNever")
+	}
+}
+
+func TestColorEmptyForce(t *testing.T) {
+	os.Unsetenv("NO_COLOR")
+	for _, key := range []string{"FORCE_COLOR", "CLICOLOR_FORCE"} {
+		os.Setenv(key, "")
+		log := &logger.Logger{Level: 4}
+		log.SetOutput(&bytes.Buffer{})
+		if log.Color {
+			t.Errorf("expected empty %s to leave color off", key)
+		}
+		os.Unsetenv(key)
+	}
+}
+
+func TestColorModeField(t *testing.T) {
+	var buf bytes.Buffer
+	log := &logger.Logger{Level: 4}
+	log.SetOutput(&buf)
+	log.ColorMode = logger.ColorAlways
+	log.Info("info")
+	if !strings.Contains(buf.String(), "\033[") {
+		t.Errorf("expected ColorAlways to apply without SetColorMode %q", buf.String())
+	}
+
+	buf.Reset()
+	log.Color = true
+	log.ColorMode = logger.ColorNever
+	log.Info("info")
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("expected ColorNever to apply without SetColorMode %q", buf.String())
+	}
+}
+
+func setOrUnset(key, value string) {
+	if value == "" {
+		os.Unsetenv(key)
+		return
+	}
+	os.Setenv(key, value)
+}
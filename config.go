@@ -0,0 +1,513 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config describes a tree of loggers. The top level settings apply to every
+// logger, Loggers overrides them by logger name and Sinks are shared by all
+// loggers.
+type Config struct {
+	Level    string                  `json:"level"`
+	Format   string                  `json:"format"`
+	Color    string                  `json:"color"`
+	Theme    string                  `json:"theme"`
+	Output   string                  `json:"output"`
+	Date     *bool                   `json:"date"`
+	UTC      *bool                   `json:"utc"`
+	Function *bool                   `json:"func"`
+	Loggers  map[string]LoggerConfig `json:"loggers"`
+	Sinks    []SinkConfig            `json:"sinks"`
+}
+
+// LoggerConfig overrides the top level settings for one logger name
+type LoggerConfig struct {
+	Level    string `json:"level"`
+	Format   string `json:"format"`
+	Color    string `json:"color"`
+	Function *bool  `json:"func"`
+}
+
+// SinkConfig describes a sink. Type is one of stdout, stderr, file, syslog,
+// journald, network, gelf or otlp. Level limits the entries the sink
+// receives below the logger level.
+type SinkConfig struct {
+	Type    string `json:"type"`
+	Level   string `json:"level"`
+	Format  string `json:"format"`
+	Color   string `json:"color"`
+	Path    string `json:"path"`
+	Network string `json:"network"`
+	Addr    string `json:"addr"`
+}
+
+// ParseConfig parses a configuration in the json, yaml or toml format
+func ParseConfig(data []byte, format string) (*Config, error) {
+	var m map[string]interface{}
+	var err error
+	switch strings.ToLower(format) {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		var c Config
+		if err := dec.Decode(&c); err != nil {
+			return nil, fmt.Errorf("log: %v", err)
+		}
+		return &c, c.check()
+	case "yaml", "yml":
+		m, err = parseYAML(data)
+	case "toml":
+		m, err = parseTOML(data)
+	default:
+		return nil, fmt.Errorf("log: unknown config format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the parsed maps are converted through json so every format is decoded
+	// into Config the same way
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("log: %v", err)
+	}
+	return ParseConfig(b, "json")
+}
+
+// check validates the names used in the configuration
+func (c *Config) check() error {
+	if err := checkNames(c.Level, c.Format, c.Color); err != nil {
+		return err
+	}
+	if _, ok := Themes[strings.ToLower(c.Theme)]; c.Theme != "" && !ok {
+		return fmt.Errorf("log: unknown theme %q", c.Theme)
+	}
+	switch strings.ToLower(c.Output) {
+	case "", "stdout", "stderr", "none":
+	default:
+		return fmt.Errorf("log: unknown output %q", c.Output)
+	}
+	for name, lc := range c.Loggers {
+		if err := checkNames(lc.Level, lc.Format, lc.Color); err != nil {
+			return fmt.Errorf("%v for logger %q", err, name)
+		}
+	}
+	for _, sc := range c.Sinks {
+		if err := checkNames(sc.Level, sc.Format, sc.Color); err != nil {
+			return fmt.Errorf("%v for sink %q", err, sc.Type)
+		}
+	}
+	return nil
+}
+
+func checkNames(lvl, format, color string) error {
+	if _, ok := level[strings.ToUpper(lvl)]; lvl != "" && !ok {
+		return fmt.Errorf("log: unknown level %q", lvl)
+	}
+	if _, ok := formats[strings.ToLower(format)]; format != "" && !ok {
+		return fmt.Errorf("log: unknown format %q", format)
+	}
+	if _, ok := parseColorMode(color); color != "" && !ok {
+		return fmt.Errorf("log: unknown color %q", color)
+	}
+	return nil
+}
+
+var colorModes = map[string]ColorMode{
+	"auto":   ColorAuto,
+	"always": ColorAlways,
+	"never":  ColorNever,
+}
+
+// parseColorMode parses auto, always, never and the booleans of parseBool,
+// an unknown mode is ColorAuto
+func parseColorMode(s string) (ColorMode, bool) {
+	if m, ok := colorModes[strings.ToLower(s)]; ok {
+		return m, true
+	}
+	b, err := parseBool(s)
+	if err != nil {
+		return ColorAuto, false
+	}
+	if b {
+		return ColorAlways, true
+	}
+	return ColorNever, true
+}
+
+// Loggers is a tree of loggers built from a configuration file. Reload and
+// Watch apply changes of the file to the loggers returned by Logger and to
+// the child loggers created from them with With. All of them write to the
+// sinks of the current configuration.
+//
+// A reload sets the settings of the file on each logger under its lock, a
+// child picks them up with its next entry. Entries are written from a copy
+// of the logger, so an entry never sees a half applied configuration. A
+// field set on a logger after a reload is used until the next reload sets
+// it again.
+type Loggers struct {
+	// OnReload is called after every reload triggered by Watch with its
+	// error
+	OnReload func(err error)
+
+	path    string
+	mu      sync.Mutex
+	loaded  atomic.Value
+	sinks   *configSinks
+	loggers map[string]*Logger
+	modTime time.Time
+	stop    chan struct{}
+}
+
+// loadedConfig is a configuration and the number of the reload that loaded
+// it
+type loadedConfig struct {
+	cfg *Config
+	gen uint64
+}
+
+// LoadConfig reads a configuration file, the format is taken from the
+// .json, .yaml, .yml or .toml extension
+func LoadConfig(path string) (*Loggers, error) {
+	t := &Loggers{path: path, sinks: newConfigSinks(), loggers: map[string]*Logger{}}
+	if err := t.Reload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Logger returns the logger called name, creating it on first use
+func (t *Loggers) Logger(name string) *Logger {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if l, ok := t.loggers[name]; ok {
+		return l
+	}
+	l := &Logger{Name: name, sinks: []Sink{t.sinks}, tree: t}
+	l.update()
+	t.loggers[name] = l
+	return l
+}
+
+// update applies the configuration loaded since the last update to a
+// logger of Loggers, l.mu must be held
+func (l *Logger) update() {
+	if l.tree == nil {
+		return
+	}
+	if c := l.tree.loaded.Load().(*loadedConfig); c.gen != l.gen {
+		c.cfg.apply(l)
+		l.gen = c.gen
+	}
+}
+
+// live returns a copy of a logger of Loggers with the current
+// configuration, taken under its lock so that a reload is never half
+// applied to an entry. Other loggers are returned as is.
+func (l *Logger) live() *Logger {
+	if l.tree == nil {
+		return l
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.update()
+	c := l.cloneLocked()
+	if c.owner = l; l.owner != nil {
+		c.owner = l.owner
+	}
+	return c
+}
+
+// Reload reads the file again and applies it to the loggers. The sinks of
+// the previous configuration are closed once the entries being written to
+// them are done.
+func (t *Loggers) Reload() error {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return err
+	}
+	cfg, err := ParseConfig(data, strings.TrimPrefix(filepath.Ext(t.path), "."))
+	if err != nil {
+		return err
+	}
+	sinks, err := cfg.buildSinks()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	gen := uint64(1)
+	if prev, ok := t.loaded.Load().(*loadedConfig); ok {
+		gen = prev.gen + 1
+	}
+	t.loaded.Store(&loadedConfig{cfg: cfg, gen: gen})
+	t.modTime = info.ModTime()
+	for _, l := range t.loggers {
+		l.mu.Lock()
+		l.update()
+		l.mu.Unlock()
+	}
+	t.mu.Unlock()
+
+	return t.sinks.replace(sinks)
+}
+
+// Watch checks the file for changes every interval and reloads it until
+// Close is called
+func (t *Loggers) Watch(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		return
+	}
+	t.stop = make(chan struct{})
+	go t.watch(interval, t.stop)
+}
+
+func (t *Loggers) watch(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(t.path)
+		if err != nil {
+			continue
+		}
+		t.mu.Lock()
+		changed := !info.ModTime().Equal(t.modTime)
+		t.mu.Unlock()
+		if !changed {
+			continue
+		}
+		err = t.Reload()
+		if t.OnReload != nil {
+			t.OnReload(err)
+		}
+		if err != nil {
+			// keep the running configuration and wait for the next change
+			t.mu.Lock()
+			t.modTime = info.ModTime()
+			t.mu.Unlock()
+		}
+	}
+}
+
+// Close stops watching and closes the sinks
+func (t *Loggers) Close() error {
+	t.mu.Lock()
+	if t.stop != nil {
+		close(t.stop)
+		t.stop = nil
+	}
+	t.mu.Unlock()
+	return t.sinks.Close()
+}
+
+func closeSinks(sinks []Sink) error {
+	var first error
+	for _, s := range sinks {
+		if err := s.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// apply configures l for its name, l.mu must be held or l not in use yet
+func (c *Config) apply(l *Logger) {
+	lvl, format, color, function := c.Level, c.Format, c.Color, c.Function
+	if lc, ok := c.Loggers[l.Name]; ok {
+		lvl, format, color = or(lc.Level, lvl), or(lc.Format, format), or(lc.Color, color)
+		if lc.Function != nil {
+			function = lc.Function
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	switch strings.ToLower(c.Output) {
+	case "stderr":
+		out = os.Stderr
+	case "none":
+		out = io.Discard
+	}
+	theme, ok := Themes[strings.ToLower(c.Theme)]
+	if !ok {
+		theme = DefaultTheme
+	}
+
+	l.Level = levelOf(lvl)
+	l.Format = formats[strings.ToLower(format)]
+	l.ColorMode, _ = parseColorMode(color)
+	l.Theme = theme
+	l.Date = isTrue(c.Date)
+	l.UTC = isTrue(c.UTC)
+	l.Function = isTrue(function)
+	l.out = out
+	l.Color = l.ColorMode.enabled(out)
+}
+
+// configSinks is the sink of the loggers of a configuration, it writes to
+// the sinks of the current configuration. Writes in progress are counted so
+// that replaced sinks are only closed when no entry is using them.
+type configSinks struct {
+	mu     sync.Mutex
+	sinks  []Sink
+	users  *sync.WaitGroup
+	closed bool
+}
+
+func newConfigSinks() *configSinks {
+	return &configSinks{users: &sync.WaitGroup{}}
+}
+
+// write writes e to the current sinks, report is called for every sink that
+// failed
+func (c *configSinks) write(e *Entry, report func(s Sink, err error)) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		report(c, errClosed)
+		return
+	}
+	sinks, users := c.sinks, c.users
+	users.Add(1)
+	c.mu.Unlock()
+	defer users.Done()
+
+	for _, s := range sinks {
+		if err := writeSink(s, e); err != nil {
+			report(s, err)
+		}
+	}
+}
+
+// Write writes e to the current sinks and returns the first error
+func (c *configSinks) Write(e *Entry) error {
+	var first error
+	c.write(e, func(_ Sink, err error) {
+		if first == nil {
+			first = err
+		}
+	})
+	return first
+}
+
+// replace makes sinks the current sinks and closes the previous ones
+func (c *configSinks) replace(sinks []Sink) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return closeSinks(sinks)
+	}
+	old, users := c.sinks, c.users
+	c.sinks, c.users = sinks, &sync.WaitGroup{}
+	c.mu.Unlock()
+
+	users.Wait()
+	return closeSinks(old)
+}
+
+// Close closes the current sinks, later writes fail
+func (c *configSinks) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	sinks, users := c.sinks, c.users
+	c.sinks = nil
+	c.mu.Unlock()
+
+	users.Wait()
+	return closeSinks(sinks)
+}
+
+func (c *Config) buildSinks() ([]Sink, error) {
+	var sinks []Sink
+	for _, sc := range c.Sinks {
+		s, err := sc.build()
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+func (sc SinkConfig) build() (Sink, error) {
+	format := formats[strings.ToLower(sc.Format)]
+	var s Sink
+	switch strings.ToLower(sc.Type) {
+	case "stdout", "stderr", "file":
+		var w io.Writer = os.Stdout
+		if sc.Type == "stderr" {
+			w = os.Stderr
+		}
+		if sc.Type == "file" {
+			f, err := os.OpenFile(sc.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return nil, err
+			}
+			w = f
+		}
+		ws := NewWriterSink(w, levelOf(sc.Level), format)
+		mode, _ := parseColorMode(sc.Color)
+		ws.SetColorMode(mode)
+		return ws, nil
+	case "syslog":
+		s = NewSyslogSink(sc.Network, sc.Addr)
+	case "journald":
+		s = NewJournalSink()
+	case "network":
+		n := NewNetworkSink(sc.Network, sc.Addr)
+		n.Format = format
+		s = n
+	case "gelf":
+		s = NewGELFSink(sc.Network, sc.Addr)
+	case "otlp":
+		s = NewOTLPExporter(sc.Addr)
+	default:
+		return nil, fmt.Errorf("log: unknown sink type %q", sc.Type)
+	}
+	if sc.Level != "" {
+		s = LevelSink(s, levelOf(sc.Level))
+	}
+	return s, nil
+}
+
+// levelOf returns the level called name, INFO when it is empty
+func levelOf(name string) int {
+	if name == "" {
+		return level["INFO"]
+	}
+	return level[strings.ToUpper(name)]
+}
+
+func isTrue(b *bool) bool {
+	return b == nil || *b
+}
+
+func or(s, fallback string) string {
+	if s != "" {
+		return s
+	}
+	return fallback
+}
@@ -0,0 +1,272 @@
+package log_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+const yamlConfig = `# service logging
+level: info
+format: text
+color: never
+output: none
+utc: false
+loggers:
+  db:
+    level: error
+  "http.client":
+    level: debug
+    format: json
+sinks:
+  - type: file
+    path: PATH
+    level: debug # everything
+    format: json
+  - type: stderr
+    level: error
+`
+
+const tomlConfig = `# service logging
+level = "info"
+format = "text"
+color = "never"
+output = "none"
+utc = false
+
+[loggers.db]
+level = "error"
+
+[loggers."http.client"]
+level = "debug"
+format = "json"
+
+[[sinks]]
+type = "file"
+path = "PATH"
+level = "debug" # everything
+format = "json"
+
+[[sinks]]
+type = "stderr"
+level = "error"
+`
+
+const jsonConfig = `{
+  "level": "info", "format": "text", "color": "never", "output": "none", "utc": false,
+  "loggers": {"db": {"level": "error"}, "http.client": {"level": "debug", "format": "json"}},
+  "sinks": [
+    {"type": "file", "path": "PATH", "level": "debug", "format": "json"},
+    {"type": "stderr", "level": "error"}
+  ]
+}`
+
+func TestParseConfig(t *testing.T) {
+	expected, err := logger.ParseConfig([]byte(jsonConfig), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected.Loggers["http.client"].Format != "json" || len(expected.Sinks) != 2 || *expected.UTC {
+		t.Fatalf("Test expected parsed config actual: %+v", expected)
+	}
+	for format, data := range map[string]string{"yaml": yamlConfig, "toml": tomlConfig} {
+		actual, err := logger.ParseConfig([]byte(data), format)
+		if err != nil {
+			t.Fatalf("Test %s: %v", format, err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Test %s expected: %+v actual: %+v", format, expected, actual)
+		}
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		data   string
+		format string
+	}{
+		{`level: loud`, "yaml"},
+		{`format = "xml"`, "toml"},
+		{`{"levle": "info"}`, "json"},
+		{"sinks:\n  - type: file\n    color: pink", "yaml"},
+		{`level: info`, "ini"},
+	}
+	for _, tt := range tests {
+		if _, err := logger.ParseConfig([]byte(tt.data), tt.format); err == nil {
+			t.Errorf("Test %q expected error", tt.data)
+		}
+	}
+}
+
+func writeConfig(t *testing.T, path, data string) {
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "app.log")
+	path := filepath.Join(dir, "log.yaml")
+	writeConfig(t, path, strings.Replace(yamlConfig, "PATH", out, 1))
+
+	tree, err := logger.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+
+	api, db, client := tree.Logger("api"), tree.Logger("db"), tree.Logger("http.client")
+	if tree.Logger("api") != api {
+		t.Errorf("Test expected the same logger for a name")
+	}
+	if api.Level != 4 || db.Level != 2 || client.Level != 6 || client.Format != logger.JSONFormat || api.UTC {
+		t.Errorf("Test expected per name settings actual: %d %d %d %v", api.Level, db.Level, client.Level, client.Format)
+	}
+	api.Info("api started")
+	db.Info("hidden")
+	client.Debug("GET /")
+
+	data, _ := os.ReadFile(out)
+	if !strings.Contains(string(data), `"msg":"api started"`) || !strings.Contains(string(data), `"msg":"GET /"`) || strings.Contains(string(data), "hidden") {
+		t.Errorf("Test expected file sink entries actual: %s", data)
+	}
+
+	writeConfig(t, path, "level: debug\noutput: none\nloggers:\n  db:\n    level: warn\n")
+	if err := tree.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if api.Debug("debug") == "" || db.Info("info") != "" || db.Warn("warn") == "" {
+		t.Errorf("Test expected reloaded levels")
+	}
+	api.Info("after reload")
+	data, _ = os.ReadFile(out)
+	if strings.Contains(string(data), "after reload") {
+		t.Errorf("Test expected removed sink actual: %s", data)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.toml")
+	writeConfig(t, path, "level = \"info\"\noutput = \"none\"\n")
+	tree, err := logger.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+	log := tree.Logger("api")
+
+	reloaded := make(chan error, 1)
+	tree.OnReload = func(err error) { reloaded <- err }
+	tree.Watch(10 * time.Millisecond)
+
+	writeConfig(t, path, "level = \"error\"\noutput = \"none\"\n")
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Test expected reload")
+	}
+	if log.Warn("warn") != "" || log.Error("error") == "" {
+		t.Errorf("Test expected ERROR level after reload")
+	}
+}
+
+func TestReloadChildAndFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.yaml")
+	writeConfig(t, path, "level: info\noutput: none\n")
+	tree, err := logger.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+	parent := tree.Logger("api")
+	child := parent.With(logger.F("request", 1))
+
+	writeConfig(t, path, "level: debug\noutput: none\n")
+	if err := tree.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if s := child.Debug("child"); !strings.HasSuffix(s, "child request=1") {
+		t.Errorf("Test expected child to follow reload actual: %q", s)
+	}
+
+	var b bytes.Buffer
+	parent.SetOutput(&b)
+	parent.Level = 1
+	if parent.Info("hidden") != "" || parent.Error("hidden too") != "" {
+		t.Errorf("Test expected level set after reload to apply")
+	}
+	parent.Level = 4
+	parent.Info("written")
+	if !strings.Contains(b.String(), "written") {
+		t.Errorf("Test expected output set after reload actual: %q", b.String())
+	}
+
+	if err := tree.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if parent.Debug("debug") == "" {
+		t.Errorf("Test expected next reload to set the level again")
+	}
+}
+
+func TestReloadConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.yaml")
+	config := func(level string) string {
+		return "level: " + level + "\noutput: none\nsinks:\n  - type: file\n    path: " + filepath.Join(dir, level+".log") + "\n"
+	}
+	writeConfig(t, path, config("info"))
+	tree, err := logger.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+
+	var errs int64
+	log := tree.Logger("api")
+	log.ErrorHandler = func(*logger.SinkError) { atomic.AddInt64(&errs, 1) }
+	child := log.With(logger.F("child", true))
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, l := range []*logger.Logger{log, child, log} {
+		wg.Add(1)
+		go func(l *logger.Logger) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				l.Info("request")
+				l.Debugf("request %d", 1)
+			}
+		}(l)
+	}
+	for i := 0; i < 20; i++ {
+		writeConfig(t, path, config([]string{"info", "debug"}[i%2]))
+		if err := tree.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if n := atomic.LoadInt64(&errs); n != 0 {
+		t.Errorf("Test expected no writes to closed sinks actual: %d errors", n)
+	}
+}
@@ -0,0 +1,365 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The YAML and TOML parsers below only cover the subset needed for
+// configuration files: nested maps, lists, strings, numbers, booleans and
+// comments. Anchors, multi-line strings, inline tables and dates are not
+// supported.
+
+type yamlLine struct {
+	n      int
+	indent int
+	text   string
+}
+
+// parseYAML parses a block style YAML document into maps, slices and scalars
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(stripComment(raw), " \r")
+		text := strings.TrimLeft(raw, " ")
+		if text == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("log: yaml line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, yamlLine{n: i + 1, indent: len(raw) - len(text), text: text})
+	}
+	if len(lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	v, next, err := parseYAMLBlock(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("log: yaml line %d: unexpected indentation", lines[next].n)
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("log: yaml document is not a map")
+	}
+	return m, nil
+}
+
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func parseYAMLBlock(lines []yamlLine, i, indent int) (interface{}, int, error) {
+	if isYAMLItem(lines[i].text) {
+		return parseYAMLList(lines, i, indent)
+	}
+	return parseYAMLMap(lines, i, indent)
+}
+
+func parseYAMLMap(lines []yamlLine, i, indent int) (interface{}, int, error) {
+	m := map[string]interface{}{}
+	for i < len(lines) && lines[i].indent == indent && !isYAMLItem(lines[i].text) {
+		line := lines[i]
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, i, fmt.Errorf("log: yaml line %d: expected key: value", line.n)
+		}
+		i++
+		if rest != "" {
+			v, err := yamlScalar(rest)
+			if err != nil {
+				return nil, i, fmt.Errorf("log: yaml line %d: %v", line.n, err)
+			}
+			m[key] = v
+			continue
+		}
+		switch {
+		case i < len(lines) && lines[i].indent > indent:
+			v, next, err := parseYAMLBlock(lines, i, lines[i].indent)
+			if err != nil {
+				return nil, next, err
+			}
+			m[key], i = v, next
+		case i < len(lines) && lines[i].indent == indent && isYAMLItem(lines[i].text):
+			v, next, err := parseYAMLList(lines, i, indent)
+			if err != nil {
+				return nil, next, err
+			}
+			m[key], i = v, next
+		default:
+			m[key] = nil
+		}
+	}
+	return m, i, nil
+}
+
+func parseYAMLList(lines []yamlLine, i, indent int) (interface{}, int, error) {
+	var list []interface{}
+	for i < len(lines) && lines[i].indent == indent && isYAMLItem(lines[i].text) {
+		line := lines[i]
+		item := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if item == "" {
+			i++
+			if i >= len(lines) || lines[i].indent <= indent {
+				list = append(list, nil)
+				continue
+			}
+			v, next, err := parseYAMLBlock(lines, i, lines[i].indent)
+			if err != nil {
+				return nil, next, err
+			}
+			list, i = append(list, v), next
+			continue
+		}
+		if _, _, ok := splitYAMLKey(item); ok {
+			// "- key: value" starts a map indented at the position of key
+			lines[i] = yamlLine{n: line.n, indent: indent + len(line.text) - len(item), text: item}
+			v, next, err := parseYAMLMap(lines, i, lines[i].indent)
+			if err != nil {
+				return nil, next, err
+			}
+			list, i = append(list, v), next
+			continue
+		}
+		v, err := yamlScalar(item)
+		if err != nil {
+			return nil, i, fmt.Errorf("log: yaml line %d: %v", line.n, err)
+		}
+		list = append(list, v)
+		i++
+	}
+	return list, i, nil
+}
+
+// splitYAMLKey splits "key: value" and "key:", quoted keys are unquoted
+func splitYAMLKey(text string) (string, string, bool) {
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return "", "", false
+		}
+		key, rest := text[1:end+1], text[end+2:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+	if strings.HasSuffix(text, ":") {
+		return text[:len(text)-1], "", true
+	}
+	if i := strings.Index(text, ": "); i > 0 {
+		return text[:i], strings.TrimSpace(text[i+2:]), true
+	}
+	return "", "", false
+}
+
+func yamlScalar(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case strings.HasPrefix(s, "["):
+		return flowList(s, yamlScalar)
+	}
+	switch s {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "~":
+		return nil, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return s, nil
+}
+
+// parseTOML parses tables, arrays of tables and key value pairs
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	cur := root
+	for i, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+		var err error
+		switch {
+		case strings.HasPrefix(line, "[["):
+			if !strings.HasSuffix(line, "]]") {
+				return nil, fmt.Errorf("log: toml line %d: expected ]]", i+1)
+			}
+			cur, err = tomlArrayTable(root, tomlKey(line[2:len(line)-2]))
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("log: toml line %d: expected ]", i+1)
+			}
+			cur, err = tomlTable(root, tomlKey(line[1:len(line)-1]))
+		default:
+			eq := strings.IndexByte(line, '=')
+			if eq < 0 {
+				return nil, fmt.Errorf("log: toml line %d: expected key = value", i+1)
+			}
+			var v interface{}
+			if v, err = tomlValue(strings.TrimSpace(line[eq+1:])); err == nil {
+				path := tomlKey(line[:eq])
+				var t map[string]interface{}
+				if t, err = tomlTable(cur, path[:len(path)-1]); err == nil {
+					t[path[len(path)-1]] = v
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("log: toml line %d: %v", i+1, err)
+		}
+	}
+	return root, nil
+}
+
+// tomlKey splits a dotted key, quoted parts are unquoted
+func tomlKey(s string) []string {
+	var path []string
+	for _, part := range split(s, '.') {
+		part = strings.TrimSpace(part)
+		if u, err := strconv.Unquote(part); err == nil {
+			part = u
+		}
+		path = append(path, strings.Trim(part, "'"))
+	}
+	return path
+}
+
+// tomlTable returns the table at path below t, creating missing tables. The
+// last element of an array of tables is used.
+func tomlTable(t map[string]interface{}, path []string) (map[string]interface{}, error) {
+	for _, k := range path {
+		switch v := t[k].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			t[k] = next
+			t = next
+		case map[string]interface{}:
+			t = v
+		case []interface{}:
+			if len(v) == 0 {
+				return nil, fmt.Errorf("%s is not a table", k)
+			}
+			last, ok := v[len(v)-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s is not a table", k)
+			}
+			t = last
+		default:
+			return nil, fmt.Errorf("%s is not a table", k)
+		}
+	}
+	return t, nil
+}
+
+func tomlArrayTable(root map[string]interface{}, path []string) (map[string]interface{}, error) {
+	parent, err := tomlTable(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	k := path[len(path)-1]
+	list, _ := parent[k].([]interface{})
+	if parent[k] != nil && list == nil {
+		return nil, fmt.Errorf("%s is not an array of tables", k)
+	}
+	t := map[string]interface{}{}
+	parent[k] = append(list, t)
+	return t, nil
+}
+
+func tomlValue(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	case strings.HasPrefix(s, "["):
+		return flowList(s, tomlValue)
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s", s)
+	}
+	return f, nil
+}
+
+// flowList parses a single line [a, b] list of scalars
+func flowList(s string, scalar func(string) (interface{}, error)) (interface{}, error) {
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("unterminated list %s", s)
+	}
+	list := []interface{}{}
+	for _, item := range split(s[1:len(s)-1], ',') {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		v, err := scalar(item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// split splits s on sep outside quotes
+func split(s string, sep byte) []string {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// stripComment removes a # comment that is not inside quotes
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,139 @@
+package log
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextExtractor returns the fields to add to an entry logged with ctx
+type ContextExtractor func(ctx context.Context) []Field
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries l
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by NewContext. When there is
+// none the default logger is returned.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
+
+// ContextValue returns an extractor that adds the value stored in the
+// context under key as a field called name
+func ContextValue(name string, key interface{}) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		if v := ctx.Value(key); v != nil {
+			return []Field{{Key: name, Value: v}}
+		}
+		return nil
+	}
+}
+
+// withContext adds the fields of the logger's extractors to e
+func (l *Logger) withContext(ctx context.Context, e *Entry) *Entry {
+	if ctx == nil {
+		return e
+	}
+	for _, x := range l.Extractors {
+		if fields := x(ctx); len(fields) > 0 {
+			e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], fields...)
+		}
+	}
+	return e
+}
+
+// DebugCtx logs debug messages with fields from ctx
+func (l *Logger) DebugCtx(ctx context.Context, msg string) string {
+	l = l.live()
+	if l.Level >= level["DEBUG"] {
+		return l.log(l.withContext(ctx, l.newEntry("DEBUG", msg)))
+	}
+	return ""
+}
+
+// DebugfCtx logs debug messages with fields from ctx
+func (l *Logger) DebugfCtx(ctx context.Context, format string, args ...interface{}) string {
+	l = l.live()
+	if l.Level >= level["DEBUG"] {
+		return l.log(l.withContext(ctx, l.newEntry("DEBUG", fmt.Sprintf(format, args...)).withTemplate(format)))
+	}
+	return ""
+}
+
+// TraceCtx logs trace messages with fields from ctx
+func (l *Logger) TraceCtx(ctx context.Context, msg string) string {
+	l = l.live()
+	if l.Level >= level["TRACE"] {
+		return l.log(l.withContext(ctx, l.newEntry("TRACE", msg)))
+	}
+	return ""
+}
+
+// TracefCtx logs trace messages with fields from ctx
+func (l *Logger) TracefCtx(ctx context.Context, format string, args ...interface{}) string {
+	l = l.live()
+	if l.Level >= level["TRACE"] {
+		return l.log(l.withContext(ctx, l.newEntry("TRACE", fmt.Sprintf(format, args...)).withTemplate(format)))
+	}
+	return ""
+}
+
+// InfoCtx logs info messages with fields from ctx
+func (l *Logger) InfoCtx(ctx context.Context, msg string) string {
+	l = l.live()
+	if l.Level >= level["INFO"] {
+		return l.log(l.withContext(ctx, l.newEntry("INFO", msg)))
+	}
+	return ""
+}
+
+// InfofCtx logs info messages with fields from ctx
+func (l *Logger) InfofCtx(ctx context.Context, format string, args ...interface{}) string {
+	l = l.live()
+	if l.Level >= level["INFO"] {
+		return l.log(l.withContext(ctx, l.newEntry("INFO", fmt.Sprintf(format, args...)).withTemplate(format)))
+	}
+	return ""
+}
+
+// WarnCtx logs warn messages with fields from ctx
+func (l *Logger) WarnCtx(ctx context.Context, msg string) string {
+	l = l.live()
+	if l.Level >= level["WARN"] {
+		return l.log(l.withContext(ctx, l.newEntry("WARN", msg)))
+	}
+	return ""
+}
+
+// WarnfCtx logs warn messages with fields from ctx
+func (l *Logger) WarnfCtx(ctx context.Context, format string, args ...interface{}) string {
+	l = l.live()
+	if l.Level >= level["WARN"] {
+		return l.log(l.withContext(ctx, l.newEntry("WARN", fmt.Sprintf(format, args...)).withTemplate(format)))
+	}
+	return ""
+}
+
+// ErrorCtx logs error messages with fields from ctx
+func (l *Logger) ErrorCtx(ctx context.Context, msg string) string {
+	l = l.live()
+	if l.Level >= level["ERROR"] {
+		return l.log(l.withContext(ctx, l.newEntry("ERROR", msg)))
+	}
+	return ""
+}
+
+// ErrorfCtx logs error messages with fields from ctx
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, args ...interface{}) string {
+	l = l.live()
+	if l.Level >= level["ERROR"] {
+		return l.log(l.withContext(ctx, l.newEntry("ERROR", fmt.Sprintf(format, args...)).withTemplate(format)))
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+type ctxKey string
+
+func TestContextRoundTrip(t *testing.T) {
+	log := &logger.Logger{Level: 4}
+	ctx := logger.NewContext(context.Background(), log)
+	if logger.FromContext(ctx) != log {
+		t.Errorf("expected logger from context")
+	}
+	if logger.FromContext(context.Background()) == nil {
+		t.Errorf("expected fallback logger")
+	}
+}
+
+func TestContextExtractors(t *testing.T) {
+	log := &logger.Logger{Level: 6}
+	log.SetOutput(&bytes.Buffer{})
+	log.Extractors = []logger.ContextExtractor{
+		logger.ContextValue("request_id", ctxKey("request")),
+		logger.ContextValue("tenant", ctxKey("tenant")),
+		func(ctx context.Context) []logger.Field {
+			if u, ok := ctx.Value(ctxKey("user")).(string); ok {
+				return []logger.Field{logger.F("user", strings.ToUpper(u))}
+			}
+			return nil
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey("request"), "r1")
+	ctx = context.WithValue(ctx, ctxKey("user"), "bob")
+	child := log.With(logger.F("svc", "api"))
+
+	var tests = []struct {
+		s     string
+		regex string
+	}{
+		{child.DebugCtx(ctx, "debug"), `DEBUG debug svc=api request_id=r1 user=BOB$`},
+		{child.TracefCtx(ctx, "%v", "trace"), `TRACE trace svc=api request_id=r1 user=BOB$`},
+		{child.InfoCtx(ctx, "info"), `INFO info svc=api request_id=r1 user=BOB$`},
+		{child.WarnfCtx(ctx, "%v", "warn"), `WARN warn svc=api request_id=r1 user=BOB$`},
+		{child.ErrorCtx(context.Background(), "error"), `ERROR error svc=api$`},
+		{child.Info("info"), `INFO info svc=api$`},
+	}
+
+	for i, tt := range tests {
+		if !regexp.MustCompile(tt.regex).MatchString(tt.s) {
+			t.Errorf("Test(%d) expected: %v actual: %v", i, tt.regex, tt.s)
+		}
+	}
+}
+
+func TestContextLevel(t *testing.T) {
+	log := &logger.Logger{Level: 3}
+	log.SetOutput(&bytes.Buffer{})
+	if s := log.InfoCtx(context.Background(), "info"); s != "" {
+		t.Errorf("Test expected no output actual: %v", s)
+	}
+	if s := log.ErrorfCtx(context.Background(), "%v", "error"); !strings.HasSuffix(s, "ERROR error") {
+		t.Errorf("Test expected error actual: %v", s)
+	}
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Dedup collapses consecutive identical entries, same logger, level,
+// message and fields, written within Window of the first one. The repeats
+// are dropped and reported by a "last message repeated N times" entry when
+// a different entry is written, the window ends or the logger is closed.
+type Dedup struct {
+	Window time.Duration
+
+	mu       sync.Mutex
+	last     string
+	level    string
+	start    time.Time
+	repeated int
+	logger   *Logger
+	timer    *time.Timer
+}
+
+// NewDedup creates a deduplicator with the given window
+func NewDedup(window time.Duration) *Dedup {
+	return &Dedup{Window: window}
+}
+
+// check reports whether e is written. The repeats of the previous entry are
+// reported first through the logger that wrote them.
+func (d *Dedup) check(l *Logger, e *Entry) bool {
+	key := fmt.Sprintf("%s\x00%s\x00%s\x00%v", e.Logger, e.Level, e.Message, e.Fields)
+	now := time.Now()
+
+	d.mu.Lock()
+	if key == d.last && now.Sub(d.start) < d.Window {
+		d.repeated++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.Window-now.Sub(d.start), d.flush)
+		}
+		d.mu.Unlock()
+		return false
+	}
+	logger, report := d.report()
+	d.last, d.level, d.start, d.logger = key, e.Level, now, l
+	d.mu.Unlock()
+
+	// the report is written without holding the lock so hooks and sinks
+	// can log through the same logger
+	if report != nil {
+		logger.emit(report)
+	}
+	return true
+}
+
+// flush reports pending repeats, it is called when the window ends without
+// a new entry and when the logger is closed
+func (d *Dedup) flush() {
+	d.mu.Lock()
+	logger, report := d.report()
+	d.last = ""
+	d.mu.Unlock()
+	if report != nil {
+		logger.emit(report)
+	}
+}
+
+// report stops the timer and returns the entry reporting the repeats and
+// the logger to write it with, nil when there were no repeats
+func (d *Dedup) report() (*Logger, *Entry) {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.repeated == 0 {
+		return nil, nil
+	}
+	e := d.logger.summary(summary{
+		level: d.level,
+		msg:   fmt.Sprintf("last message repeated %d times", d.repeated),
+	})
+	d.repeated = 0
+	return d.logger, e
+}
@@ -0,0 +1,90 @@
+package log_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestDedup(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4, Dedup: logger.NewDedup(time.Hour)}
+	log.SetOutput(&b)
+	child := log.With(logger.F("attempt", 1))
+
+	for i := 0; i < 4; i++ {
+		log.Warn("connection refused")
+	}
+	child.Warn("connection refused")
+	log.Error("connection refused")
+	log.Error("connection refused")
+	log.Close()
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		messages = append(messages, line[13:])
+	}
+	expected := []string{
+		"WARN connection refused",
+		"WARN last message repeated 3 times",
+		"WARN connection refused attempt=1",
+		"ERROR connection refused",
+		"ERROR last message repeated 1 times",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Test expected: %q actual: %q", expected, messages)
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	sink := &memSink{}
+	log := &logger.Logger{Level: 4, Dedup: logger.NewDedup(30 * time.Millisecond)}
+	log.SetOutput(io.Discard)
+	log.AddSink(sink)
+
+	log.Info("tick")
+	log.Info("tick")
+	log.Info("tick")
+	time.Sleep(60 * time.Millisecond)
+	log.Info("tick")
+
+	expected := []string{"tick", "last message repeated 2 times", "tick"}
+	if got := sink.messages(); strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Test expected: %v actual: %v", expected, got)
+	}
+}
+
+func TestDedupReentrantHook(t *testing.T) {
+	sink := &memSink{}
+	log := &logger.Logger{Level: 4, Dedup: logger.NewDedup(time.Hour)}
+	log.SetOutput(io.Discard)
+	log.AddSink(sink)
+	log.AddHook(logger.HookFunc(func(e *logger.Entry) bool {
+		if strings.HasPrefix(e.Message, "last message repeated") {
+			log.Info("repeats reported")
+		}
+		return true
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		log.Info("tick")
+		log.Info("tick")
+		log.Info("tock")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test expected hook to log while reporting repeats")
+	}
+
+	expected := []string{"tick", "repeats reported", "last message repeated 1 times", "tock"}
+	if got := sink.messages(); strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Test expected: %v actual: %v", expected, got)
+	}
+}
@@ -0,0 +1,171 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger *Logger
+)
+
+// Default returns the logger used by the package level functions. Until
+// SetDefault is called it is a logger configured from the environment.
+func Default() *Logger {
+	defaultMu.RLock()
+	l := defaultLogger
+	defaultMu.RUnlock()
+	if l != nil {
+		return l
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultLogger == nil {
+		defaultLogger = NewLogger("")
+	}
+	return defaultLogger
+}
+
+// SetDefault makes l the logger used by the package level functions and
+// FromContext
+func SetDefault(l *Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = l
+}
+
+// With returns a child of the default logger that adds fields to every
+// entry
+func With(fields ...Field) *Logger {
+	return Default().With(fields...)
+}
+
+// The package level functions call newEntry themselves rather than the
+// methods of the default logger so the caller is found at the same depth.
+
+// Debug logs debug messages with the default logger
+func Debug(msg string) string {
+	l := Default().live()
+	if l.Level >= level["DEBUG"] {
+		return l.log(l.newEntry("DEBUG", msg))
+	}
+	return ""
+}
+
+// Debugf logs debug messages with the default logger
+func Debugf(format string, args ...interface{}) string {
+	l := Default().live()
+	if l.Level >= level["DEBUG"] {
+		return l.log(l.newEntry("DEBUG", fmt.Sprintf(format, args...)).withTemplate(format))
+	}
+	return ""
+}
+
+// Trace logs trace messages with the default logger
+func Trace(msg string) string {
+	l := Default().live()
+	if l.Level >= level["TRACE"] {
+		return l.log(l.newEntry("TRACE", msg))
+	}
+	return ""
+}
+
+// Tracef logs trace messages with the default logger
+func Tracef(format string, args ...interface{}) string {
+	l := Default().live()
+	if l.Level >= level["TRACE"] {
+		return l.log(l.newEntry("TRACE", fmt.Sprintf(format, args...)).withTemplate(format))
+	}
+	return ""
+}
+
+// Info logs info messages with the default logger
+func Info(msg string) string {
+	l := Default().live()
+	if l.Level >= level["INFO"] {
+		return l.log(l.newEntry("INFO", msg))
+	}
+	return ""
+}
+
+// Infof logs info messages with the default logger
+func Infof(format string, args ...interface{}) string {
+	l := Default().live()
+	if l.Level >= level["INFO"] {
+		return l.log(l.newEntry("INFO", fmt.Sprintf(format, args...)).withTemplate(format))
+	}
+	return ""
+}
+
+// Warn logs warn messages with the default logger
+func Warn(msg string) string {
+	l := Default().live()
+	if l.Level >= level["WARN"] {
+		return l.log(l.newEntry("WARN", msg))
+	}
+	return ""
+}
+
+// Warnf logs warn messages with the default logger
+func Warnf(format string, args ...interface{}) string {
+	l := Default().live()
+	if l.Level >= level["WARN"] {
+		return l.log(l.newEntry("WARN", fmt.Sprintf(format, args...)).withTemplate(format))
+	}
+	return ""
+}
+
+// Error logs error messages with the default logger
+func Error(msg string) string {
+	l := Default().live()
+	if l.Level >= level["ERROR"] {
+		return l.log(l.newEntry("ERROR", msg))
+	}
+	return ""
+}
+
+// Errorf logs error messages with the default logger
+func Errorf(format string, args ...interface{}) string {
+	l := Default().live()
+	if l.Level >= level["ERROR"] {
+		return l.log(l.newEntry("ERROR", fmt.Sprintf(format, args...)).withTemplate(format))
+	}
+	return ""
+}
+
+// Fatal logs fatal message with the default logger and exits (1)
+func Fatal(msg string) string {
+	l := Default().live()
+	s := l.log(l.newEntry("FATAL", msg))
+	l.Close()
+	defer os.Exit(1)
+	return s
+}
+
+// Fatalf logs fatal message with the default logger and exits (1)
+func Fatalf(format string, args ...interface{}) string {
+	l := Default().live()
+	s := l.log(l.newEntry("FATAL", fmt.Sprintf(format, args...)).withTemplate(format))
+	l.Close()
+	defer os.Exit(1)
+	return s
+}
+
+// Panic logs panic message with the default logger and panics
+func Panic(msg string) string {
+	l := Default().live()
+	s := l.format(l.redact(l.newEntry("PANIC", msg)))
+	defer panic(s)
+	return s
+}
+
+// Panicf logs panic message with the default logger and panics
+func Panicf(format string, args ...interface{}) string {
+	l := Default().live()
+	s := l.format(l.redact(l.newEntry("PANIC", fmt.Sprintf(format, args...)).withTemplate(format)))
+	defer panic(s)
+	return s
+}
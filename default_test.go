@@ -0,0 +1,79 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestDefault(t *testing.T) {
+	old := logger.Default()
+	defer logger.SetDefault(old)
+
+	var b bytes.Buffer
+	log := logger.New(logger.WithLevel(6), logger.WithOutput(&b), logger.WithDate(false))
+	logger.SetDefault(log)
+	if logger.Default() != log || logger.FromContext(context.Background()) != log {
+		t.Fatalf("Test expected the default logger")
+	}
+
+	tests := []struct {
+		fn       func() (string, int)
+		expected string
+	}{
+		{func() (string, int) { return logger.Debug("a"), line() }, "DEBUG"},
+		{func() (string, int) { return logger.Debugf("%s", "a"), line() }, "DEBUG"},
+		{func() (string, int) { return logger.Trace("a"), line() }, "TRACE"},
+		{func() (string, int) { return logger.Tracef("%s", "a"), line() }, "TRACE"},
+		{func() (string, int) { return logger.Info("a"), line() }, "INFO"},
+		{func() (string, int) { return logger.Infof("%s", "a"), line() }, "INFO"},
+		{func() (string, int) { return logger.Warn("a"), line() }, "WARN"},
+		{func() (string, int) { return logger.Warnf("%s", "a"), line() }, "WARN"},
+		{func() (string, int) { return logger.Error("a"), line() }, "ERROR"},
+		{func() (string, int) { return logger.Errorf("%s", "a"), line() }, "ERROR"},
+		{func() (string, int) { return logger.With(logger.F("k", 1)).Info("a"), line() }, "INFO"},
+	}
+	for i, tt := range tests {
+		s, l := tt.fn()
+		expected := tt.expected + " [default_test.go:" + strconv.Itoa(l) + "] a"
+		if !strings.Contains(s, expected) {
+			t.Errorf("Test(%d) expected: %s actual: %s", i, expected, s)
+		}
+	}
+	if strings.Count(b.String(), "\n") != len(tests) {
+		t.Errorf("Test expected: %d lines actual: %s", len(tests), b.String())
+	}
+
+	log.Level = 2
+	if logger.Info("hidden") != "" {
+		t.Errorf("Test expected level of the default logger")
+	}
+}
+
+func TestDefaultConcurrent(t *testing.T) {
+	old := logger.Default()
+	defer logger.SetDefault(old)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			log := logger.New(logger.WithLevel(2), logger.WithOutput(&bytes.Buffer{}))
+			logger.SetDefault(log)
+		}()
+		go func() {
+			defer wg.Done()
+			if logger.Default() == nil {
+				t.Errorf("Test expected a default logger")
+			}
+			logger.Info("ignored")
+		}()
+	}
+	wg.Wait()
+}
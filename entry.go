@@ -0,0 +1,55 @@
+package log
+
+import (
+	"time"
+)
+
+// Entry is a single log entry before it is formatted
+type Entry struct {
+	Logger  string
+	Time    time.Time
+	Level   string
+	Caller  Caller
+	Message string
+	Fields  []Field
+	Stack   []Caller
+
+	// template is the format string of the f methods, entries are grouped
+	// by it for sampling
+	template string
+}
+
+// newEntry must be called directly from the exported logging methods so the
+// caller is found at a fixed depth
+func (l *Logger) newEntry(logLevel string, msg string) *Entry {
+	e := &Entry{
+		Logger:  l.Name,
+		Time:    time.Now(),
+		Level:   logLevel,
+		Message: msg,
+		Fields:  l.fields,
+	}
+	if l.UTC {
+		e.Time = e.Time.UTC()
+	}
+	if l.Function || l.RateLimiter != nil && l.RateLimiter.PerCaller != (Rate{}) {
+		e.Caller = caller(2 + l.CallerSkip)
+	}
+	if l.wantStack(logLevel) {
+		e.Stack = stack(2 + l.CallerSkip)
+	}
+	return e
+}
+
+func (e *Entry) withTemplate(format string) *Entry {
+	e.template = format
+	return e
+}
+
+// key returns the template of e or its message when it was not formatted
+func (e *Entry) key() string {
+	if e.template != "" {
+		return e.template
+	}
+	return e.Message
+}
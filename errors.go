@@ -0,0 +1,96 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Err creates an error field. Error fields record the message, the
+// concrete types of the wrapped error chain and any stack trace the error
+// prints with %+v.
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// errorDetail is the structured form of an error field
+type errorDetail struct {
+	Message string        `json:"msg"`
+	Type    string        `json:"type"`
+	Chain   []errorDetail `json:"chain,omitempty"`
+	Stack   string        `json:"stack,omitempty"`
+}
+
+// newErrorDetail describes err, the rules of a redacted error are applied
+// to every message of the chain and to the verbose form
+func newErrorDetail(err error, mode SanitizeMode) errorDetail {
+	apply := func(s string) string { return s }
+	if r, ok := err.(redactedError); ok {
+		err, apply = r.err, r.redactor.apply
+	}
+	d := chainDetail(err, apply, mode)
+	if v := fmt.Sprintf("%+v", err); v != err.Error() {
+		d.Stack = sanitizeLines(apply(v), mode)
+	}
+	return d
+}
+
+func chainDetail(err error, apply func(string) string, mode SanitizeMode) errorDetail {
+	d := errorDetail{Message: sanitize(apply(err.Error()), mode), Type: fmt.Sprintf("%T", err)}
+	for _, c := range unwrap(err) {
+		if c != nil {
+			d.Chain = append(d.Chain, chainDetail(c, apply, mode))
+		}
+	}
+	return d
+}
+
+// unwrap returns the errors wrapped by err, supporting both the single
+// Unwrap and the errors.Join form
+func unwrap(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return []error{u.Unwrap()}
+	case interface{ Unwrap() []error }:
+		return u.Unwrap()
+	}
+	return nil
+}
+
+// types lists the concrete types of the error and its chain, depth first
+func (d errorDetail) types() []string {
+	t := []string{d.Type}
+	for _, c := range d.Chain {
+		t = append(t, c.types()...)
+	}
+	return t
+}
+
+// formatErrorStack indents a verbose error under the entry
+func formatErrorStack(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "\n\t" + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n\t")
+}
+
+// ErrorE logs error messages with err attached as an error field
+func (l *Logger) ErrorE(err error, msg string) string {
+	l = l.live()
+	if l.Level >= level["ERROR"] {
+		e := l.newEntry("ERROR", msg)
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], Err(err))
+		return l.log(e)
+	}
+	return ""
+}
+
+// ErrorEf logs error messages with err attached as an error field
+func (l *Logger) ErrorEf(err error, format string, args ...interface{}) string {
+	l = l.live()
+	if l.Level >= level["ERROR"] {
+		e := l.newEntry("ERROR", fmt.Sprintf(format, args...)).withTemplate(format)
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], Err(err))
+		return l.log(e)
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+type stackError struct{ msg string }
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	io.WriteString(s, e.msg)
+	if verb == 'v' && s.Flag('+') {
+		io.WriteString(s, "\nmain.handle\n\t/src/main.go:10")
+	}
+}
+
+func TestErrorE(t *testing.T) {
+	log := &logger.Logger{Level: 4}
+	log.SetOutput(&bytes.Buffer{})
+
+	err := fmt.Errorf("read config: %w", io.EOF)
+	s := log.ErrorE(err, "failed")
+	re := regexp.MustCompile(`^\S+ ERROR failed error="read config: EOF" error_types=\*fmt.wrapError,\*errors.errorString$`)
+	if !re.MatchString(s) {
+		t.Errorf("Test expected: %v actual: %v", re, s)
+	}
+
+	s = log.ErrorEf(nil, "%v", "failed")
+	if !strings.HasSuffix(s, "ERROR failed error=<nil>") {
+		t.Errorf("Test expected nil error actual: %v", s)
+	}
+
+	log.Level = 1
+	if s := log.ErrorE(err, "failed"); s != "" {
+		t.Errorf("Test expected no output actual: %v", s)
+	}
+}
+
+func TestErrField(t *testing.T) {
+	log := &logger.Logger{Level: 4}
+	log.SetOutput(&bytes.Buffer{})
+
+	s := log.With(logger.Err(&stackError{"boom"})).Warn("warn")
+	expected := "WARN warn error=boom error_types=*log_test.stackError\n\tboom\n\tmain.handle\n\t\t/src/main.go:10"
+	if !strings.HasSuffix(s, expected) {
+		t.Errorf("Test expected suffix: %q actual: %q", expected, s)
+	}
+}
+
+func TestErrFieldSanitized(t *testing.T) {
+	log := &logger.Logger{Level: 4, Sanitize: logger.SanitizeStrip}
+	log.SetOutput(&bytes.Buffer{})
+
+	s := log.ErrorE(&stackError{"boom \x1b[31mred\r\nINFO forged"}, "failed")
+	if strings.Contains(s, "\x1b") || strings.Contains(s, "\r") {
+		t.Errorf("Test expected sanitized error stack actual: %q", s)
+	}
+	for _, line := range strings.Split(s, "\n")[1:] {
+		if !strings.HasPrefix(line, "\t") {
+			t.Errorf("Test expected indented stack lines actual: %q", s)
+		}
+	}
+}
+
+func TestErrorJSON(t *testing.T) {
+	log := &logger.Logger{Level: 4, Format: logger.JSONFormat}
+	log.SetOutput(&bytes.Buffer{})
+
+	err := fmt.Errorf("wrap: %w", errors.Join(io.EOF, &stackError{"boom"}))
+	s := log.ErrorE(err, "failed")
+
+	var m struct {
+		Error struct {
+			Msg   string `json:"msg"`
+			Type  string `json:"type"`
+			Stack string `json:"stack"`
+			Chain []struct {
+				Type  string `json:"type"`
+				Chain []struct {
+					Msg  string `json:"msg"`
+					Type string `json:"type"`
+				} `json:"chain"`
+			} `json:"chain"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatalf("invalid json %v: %v", err, s)
+	}
+	if m.Error.Msg != "wrap: EOF\\nboom" || m.Error.Type != "*fmt.wrapError" {
+		t.Errorf("unexpected error %+v", m.Error)
+	}
+	if len(m.Error.Chain) != 1 || m.Error.Chain[0].Type != "*errors.joinError" || len(m.Error.Chain[0].Chain) != 2 {
+		t.Fatalf("unexpected chain %+v", m.Error.Chain)
+	}
+	if c := m.Error.Chain[0].Chain[1]; c.Msg != "boom" || c.Type != "*log_test.stackError" {
+		t.Errorf("unexpected chain entry %+v", c)
+	}
+	if m.Error.Stack != "" {
+		t.Errorf("unexpected stack %v", m.Error.Stack)
+	}
+}
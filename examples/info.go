@@ -1,5 +1,7 @@
 package main
 
+import logger "github.com/casonadams/simple-logger"
+
 func info() {
-	log.Info("Info message")
+	logger.Info("Info message")
 }
@@ -8,17 +8,15 @@ import (
 	logger "github.com/casonadams/simple-logger"
 )
 
-var log logger.Logger
-
 func main() {
-	log = *logger.NewLogger("test")
-	log.Debug("Debug message")
-	log.Trace("Trace message")
+	logger.SetDefault(logger.NewLogger("test"))
+	logger.Debug("Debug message")
+	logger.Trace("Trace message")
 	info()
 	warn()
-	log.Error("Error message")
-	log.Fatal("Fatal message")
-	log.Panic("Panic message")
+	logger.Error("Error message")
+	logger.Fatal("Fatal message")
+	logger.Panic("Panic message")
 
 	// code never reaches here comment out above to see how fast the logger is
 
@@ -32,7 +30,7 @@ func main() {
 func trace2() {
 	for {
 		s := time.Now()
-		log.Trace(fmt.Sprintf("\033[%dm%s\033[0m", 96, "trace message"))
+		logger.Trace(fmt.Sprintf("\033[%dm%s\033[0m", 96, "trace message"))
 		e := time.Now()
 		fmt.Printf("%v\n", e.Sub(s))
 		n := rand.Intn(1000) // n will be between 0 and 1000
@@ -43,7 +41,7 @@ func trace2() {
 func info2() {
 	for {
 		s := time.Now()
-		log.Info(fmt.Sprintf("\033[%dm%s\033[0m", 34, "info message"))
+		logger.Info(fmt.Sprintf("\033[%dm%s\033[0m", 34, "info message"))
 		e := time.Now()
 		fmt.Printf("%v\n", e.Sub(s))
 		n := rand.Intn(1000) // n will be between 0 and 1000
@@ -54,7 +52,7 @@ func info2() {
 func error2() {
 	for {
 		s := time.Now()
-		log.Error(fmt.Sprintf("\033[%dm%s\033[0m", 91, "error message"))
+		logger.Error(fmt.Sprintf("\033[%dm%s\033[0m", 91, "error message"))
 		e := time.Now()
 		fmt.Printf("%v\n", e.Sub(s))
 		n := rand.Intn(1000) // n will be between 0 and 1000
@@ -65,7 +63,7 @@ func error2() {
 func warn2() {
 	for {
 		s := time.Now()
-		log.Warn(fmt.Sprintf("\033[%dm%s\033[0m", 93, "warn message"))
+		logger.Warn(fmt.Sprintf("\033[%dm%s\033[0m", 93, "warn message"))
 		e := time.Now()
 		fmt.Printf("%v\n", e.Sub(s))
 		n := rand.Intn(1000) // n will be between 0 and 1000
@@ -76,7 +74,7 @@ func warn2() {
 func debug2() {
 	for {
 		s := time.Now()
-		log.Debug("debug message")
+		logger.Debug("debug message")
 		e := time.Now()
 		fmt.Printf("%v\n", e.Sub(s))
 		n := rand.Intn(1000) // n will be between 0 and 1000
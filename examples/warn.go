@@ -1,5 +1,7 @@
 package main
 
+import logger "github.com/casonadams/simple-logger"
+
 func warn() {
-	log.Warn("Warn message")
+	logger.Warn("Warn message")
 }
@@ -0,0 +1,11 @@
+package log
+
+import "time"
+
+// ResetStderrLimit clears the rate limit of the default error handler
+func ResetStderrLimit() {
+	stderrLimit.mu.Lock()
+	defer stderrLimit.mu.Unlock()
+	stderrLimit.last = time.Time{}
+	stderrLimit.suppressed = 0
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"strings"
+)
+
+// Field is a key value pair attached to every entry of a logger
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F creates a field
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// With returns a child logger that adds fields to every entry
+func (l *Logger) With(fields ...Field) *Logger {
+	l = l.live()
+	c := l.clone()
+	c.fields = append(c.fields, fields...)
+	return c
+}
+
+func (l *Logger) clone() *Logger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cloneLocked()
+}
+
+func (l *Logger) cloneLocked() *Logger {
+	return &Logger{
+		out:          l.out,
+		sinks:        l.sinks,
+		hooks:        l.hooks,
+		fields:       append([]Field(nil), l.fields...),
+		problems:     l.problems,
+		Name:         l.Name,
+		Level:        l.Level,
+		Date:         l.Date,
+		Color:        l.Color,
+		ColorMode:    l.ColorMode,
+		Theme:        l.Theme,
+		Function:     l.Function,
+		CallerSkip:   l.CallerSkip,
+		CallerPath:   l.CallerPath,
+		CallerFunc:   l.CallerFunc,
+		UTC:          l.UTC,
+		Format:       l.Format,
+		Sanitize:     l.Sanitize,
+		Stack:        l.Stack,
+		StackLevel:   l.StackLevel,
+		Extractors:   append([]ContextExtractor(nil), l.Extractors...),
+		ErrorHandler: l.ErrorHandler,
+		Fallback:     l.Fallback,
+		Sampler:      l.Sampler,
+		RateLimiter:  l.RateLimiter,
+		Dedup:        l.Dedup,
+		Redactor:     l.Redactor,
+		Metrics:      l.Metrics,
+		sinkCounters: l.countersLocked(),
+		tree:         l.tree,
+		gen:          l.gen,
+	}
+}
+
+func formatValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"") {
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+package log_test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	log := &logger.Logger{Level: 4}
+	log.SetOutput(&buf)
+
+	child := log.With(logger.F("id", 42), logger.F("name", "a b"))
+	grandchild := child.With(logger.F("empty", ""))
+
+	var tests = []struct {
+		log   *logger.Logger
+		regex string
+	}{
+		{log, `^\d{2}:\d{2}:\d{2}.\d{3} INFO info$`},
+		{child, `^\d{2}:\d{2}:\d{2}.\d{3} INFO info id=42 name="a b"$`},
+		{grandchild, `^\d{2}:\d{2}:\d{2}.\d{3} INFO info id=42 name="a b" empty=""$`},
+	}
+
+	for i, tt := range tests {
+		s := tt.log.Info("info")
+		if !regexp.MustCompile(tt.regex).MatchString(s) {
+			t.Errorf("Test(%d) expected: %v actual: %q", i, tt.regex, s)
+		}
+	}
+
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != len(tests) {
+		t.Errorf("expected %d lines written, actual %d", len(tests), n)
+	}
+}
@@ -0,0 +1,131 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Format selects how entries are encoded
+type Format int
+
+// Formats
+const (
+	// TextFormat is the human readable, optionally colored line
+	TextFormat Format = iota
+	// JSONFormat writes one JSON object per line
+	JSONFormat
+	// GELFFormat writes Graylog Extended Log Format 1.1 messages
+	GELFFormat
+)
+
+var formats = map[string]Format{
+	"text": TextFormat,
+	"json": JSONFormat,
+	"gelf": GELFFormat,
+}
+
+func (l *Logger) format(e *Entry) string {
+	switch l.Format {
+	case JSONFormat:
+		return l.formatJSON(e)
+	case GELFFormat:
+		return l.formatGELF(e)
+	}
+	return l.formatText(e)
+}
+
+func (l *Logger) formatText(e *Entry) string {
+	prefix := ""
+	theme := l.theme()
+	mode := l.sanitizeMode()
+
+	// Setup timesampe
+	if l.Date {
+		prefix += l.paint(e.Time.Format("2006-01-02 15:04:05.000"), theme.Time) + " "
+	} else {
+		prefix += l.paint(e.Time.Format("15:04:05.000"), theme.Time) + " "
+	}
+
+	// Logging level
+	prefix += l.paint(e.Level, theme.Levels[e.Level]) + " "
+
+	// Caller location
+	if l.Function && e.Caller.File != "" {
+		prefix += l.paint("["+l.formatCaller(e.Caller)+"]", theme.Caller) + " "
+	}
+
+	var b strings.Builder
+	var errStacks []string
+	b.WriteString(prefix)
+	b.WriteString(sanitize(e.Message, mode))
+	for _, f := range e.Fields {
+		b.WriteString(" ")
+		b.WriteString(l.paint(f.Key, theme.Key))
+		b.WriteString("=")
+		err, ok := f.Value.(error)
+		if !ok || err == nil {
+			b.WriteString(formatValue(sanitize(fmt.Sprint(f.Value), mode)))
+			continue
+		}
+		d := newErrorDetail(err, mode)
+		b.WriteString(formatValue(d.Message))
+		b.WriteString(" ")
+		b.WriteString(l.paint(f.Key+"_types", theme.Key))
+		b.WriteString("=")
+		b.WriteString(formatValue(strings.Join(d.types(), ",")))
+		errStacks = append(errStacks, d.Stack)
+	}
+	b.WriteString(formatStack(e.Stack))
+	for _, s := range errStacks {
+		b.WriteString(formatErrorStack(s))
+	}
+	return b.String()
+}
+
+func (l *Logger) formatJSON(e *Entry) string {
+	mode := l.sanitizeMode()
+
+	var b bytes.Buffer
+	b.WriteString(`{"time":`)
+	writeJSON(&b, e.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+	b.WriteString(`,"level":`)
+	writeJSON(&b, e.Level)
+	if l.Function && e.Caller.File != "" {
+		b.WriteString(`,"caller":`)
+		writeJSON(&b, l.formatCaller(e.Caller))
+	}
+	b.WriteString(`,"msg":`)
+	writeJSON(&b, sanitize(e.Message, mode))
+	for _, f := range e.Fields {
+		b.WriteString(",")
+		writeJSON(&b, f.Key)
+		b.WriteString(":")
+		switch v := f.Value.(type) {
+		case string:
+			writeJSON(&b, sanitize(v, mode))
+		case error:
+			writeJSON(&b, newErrorDetail(v, mode))
+		default:
+			writeJSON(&b, v)
+		}
+	}
+	if len(e.Stack) > 0 {
+		b.WriteString(`,"stack":`)
+		writeJSON(&b, e.Stack)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func writeJSON(b *bytes.Buffer, v interface{}) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		buf.Reset()
+		enc.Encode(fmt.Sprint(v))
+	}
+	b.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
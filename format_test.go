@@ -0,0 +1,58 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestNewLoggerFormat(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out logger.Format
+	}{
+		{"", logger.TextFormat},
+		{"text", logger.TextFormat},
+		{"json", logger.JSONFormat},
+		{"JSON", logger.JSONFormat},
+	}
+
+	for i, tt := range tests {
+		os.Setenv("LOG_FORMAT", tt.in)
+		actual := logger.NewLogger("test")
+		if tt.out != actual.Format {
+			t.Errorf("Test(%v): expected %v, actual %v", i, tt.out, actual.Format)
+		}
+	}
+	os.Unsetenv("LOG_FORMAT")
+}
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log := &logger.Logger{
+		Level:     4,
+		Function:  true,
+		UTC:       true,
+		Format:    logger.JSONFormat,
+		ColorMode: logger.ColorAlways,
+	}
+	log.SetOutput(&buf)
+	s := log.With(logger.F("id", 42), logger.F("ok", true)).Warnf("%v <b>", "warn")
+
+	re := regexp.MustCompile(`^\{"time":"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}.\d{3}Z","level":"WARN","caller":"\w+.\w+:\d+","msg":"warn <b>","id":42,"ok":true\}$`)
+	if !re.MatchString(s) {
+		t.Errorf("Test expected: %v actual: %v", re, s)
+	}
+	if buf.String() != s+"\n" {
+		t.Errorf("Test expected: %q actual: %q", s+"\n", buf.String())
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Errorf("invalid json: %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var hostname, _ = os.Hostname()
+
+func (l *Logger) formatGELF(e *Entry) string {
+	mode := l.sanitizeMode()
+
+	short := e.Message
+	if i := strings.IndexByte(short, '\n'); i >= 0 {
+		short = short[:i]
+	}
+	full := e.Message + formatStack(e.Stack)
+	for _, f := range e.Fields {
+		if err, ok := f.Value.(error); ok && err != nil {
+			full += formatErrorStack(newErrorDetail(err, SanitizeNone).Stack)
+		}
+	}
+
+	severity, ok := syslogSeverity[e.Level]
+	if !ok {
+		severity = 6
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`{"version":"1.1","host":`)
+	writeJSON(&b, hostname)
+	b.WriteString(`,"short_message":`)
+	writeJSON(&b, sanitize(short, mode))
+	if full != short {
+		b.WriteString(`,"full_message":`)
+		writeJSON(&b, ansi.ReplaceAllString(full, ""))
+	}
+	b.WriteString(`,"timestamp":`)
+	b.WriteString(fmt.Sprintf("%.3f", float64(e.Time.UnixNano())/1e9))
+	b.WriteString(`,"level":`)
+	writeJSON(&b, severity)
+	if e.Logger != "" {
+		b.WriteString(`,"_logger":`)
+		writeJSON(&b, e.Logger)
+	}
+	if l.Function && e.Caller.File != "" {
+		b.WriteString(`,"_file":`)
+		writeJSON(&b, e.Caller.File)
+		b.WriteString(`,"_line":`)
+		writeJSON(&b, e.Caller.Line)
+		b.WriteString(`,"_function":`)
+		writeJSON(&b, e.Caller.Function)
+	}
+	for _, f := range e.Fields {
+		b.WriteString(",")
+		writeJSON(&b, gelfKey(f.Key))
+		b.WriteString(":")
+		switch v := otlpValue(f.Value).(type) {
+		case int64, float64:
+			writeJSON(&b, v)
+		default:
+			writeJSON(&b, sanitize(fmt.Sprint(v), mode))
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// gelfKey prefixes a field name with _ and replaces characters GELF does not
+// allow. _id is reserved so id becomes __id.
+func gelfKey(k string) string {
+	k = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '_', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, k)
+	if k == "id" {
+		return "__id"
+	}
+	return "_" + k
+}
+
+// GELFCompression selects how UDP GELF messages are compressed
+type GELFCompression int
+
+// GELF compressions
+const (
+	GELFGzip GELFCompression = iota
+	GELFZlib
+	GELFNone
+)
+
+// gelfMaxChunks is the largest number of chunks Graylog accepts
+const gelfMaxChunks = 128
+
+// gelfChunkHeader is the size of the magic bytes, message id, sequence
+// number and count preceding every chunk
+const gelfChunkHeader = 12
+
+// GELFSink is a sink that sends GELF messages to Graylog. udp messages are
+// compressed and split into chunks of ChunkSize bytes, which must be larger
+// than the 12 byte chunk header, tcp messages are
+// uncompressed and terminated by a null byte.
+type GELFSink struct {
+	Network     string
+	Addr        string
+	Compression GELFCompression
+	ChunkSize   int
+	Timeout     time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewGELFSink creates a sink for network udp or tcp
+func NewGELFSink(network, addr string) *GELFSink {
+	return &GELFSink{
+		Network:   network,
+		Addr:      addr,
+		ChunkSize: 1420,
+		Timeout:   5 * time.Second,
+	}
+}
+
+// Write sends e to Graylog, reconnecting once if the connection failed
+func (g *GELFSink) Write(e *Entry) error {
+	enc := &Logger{Format: GELFFormat, Function: true}
+	msg := []byte(enc.format(e))
+
+	var packets [][]byte
+	if g.Network == "udp" {
+		var err error
+		if msg, err = g.compress(msg); err != nil {
+			return err
+		}
+		if packets, err = g.chunks(msg); err != nil {
+			return err
+		}
+	} else {
+		packets = [][]byte{append(msg, 0)}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if g.conn == nil {
+			if g.conn, err = net.DialTimeout(g.Network, g.Addr, g.Timeout); err != nil {
+				g.conn = nil
+				continue
+			}
+		}
+		for _, p := range packets {
+			if _, err = g.conn.Write(p); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			return nil
+		}
+		g.conn.Close()
+		g.conn = nil
+	}
+	return err
+}
+
+// Close closes the connection
+func (g *GELFSink) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
+
+func (g *GELFSink) compress(msg []byte) ([]byte, error) {
+	var b bytes.Buffer
+	switch g.Compression {
+	case GELFGzip:
+		w := gzip.NewWriter(&b)
+		w.Write(msg)
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	case GELFZlib:
+		w := zlib.NewWriter(&b)
+		w.Write(msg)
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return msg, nil
+	}
+	return b.Bytes(), nil
+}
+
+// chunks splits msg into GELF chunks when it does not fit in one datagram
+func (g *GELFSink) chunks(msg []byte) ([][]byte, error) {
+	size := g.ChunkSize
+	if size > 0 && size <= gelfChunkHeader {
+		return nil, fmt.Errorf("log: GELF ChunkSize %d must be larger than %d", size, gelfChunkHeader)
+	}
+	if size <= 0 || len(msg) <= size {
+		return [][]byte{msg}, nil
+	}
+	payload := size - gelfChunkHeader
+	count := (len(msg) + payload - 1) / payload
+	if count > gelfMaxChunks {
+		return nil, errors.New("log: GELF message too large")
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	var chunks [][]byte
+	for i := 0; i < count; i++ {
+		end := (i + 1) * payload
+		if end > len(msg) {
+			end = len(msg)
+		}
+		chunk := append([]byte{0x1e, 0x0f}, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunks = append(chunks, append(chunk, msg[i*payload:end]...))
+	}
+	return chunks, nil
+}
@@ -0,0 +1,184 @@
+package log_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestGELFFormat(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4, Format: logger.GELFFormat, Name: "billing"}
+	log.SetOutput(&b)
+	log.With(logger.F("id", 7), logger.F("bad key", true), logger.Err(errors.New("boom"))).Error("disk\nfull")
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatal(err, b.String())
+	}
+	expected := map[string]interface{}{
+		"version":       "1.1",
+		"short_message": "disk",
+		"full_message":  "disk\nfull",
+		"level":         float64(3),
+		"_logger":       "billing",
+		"__id":          float64(7),
+		"_bad_key":      "true",
+		"_error":        "boom",
+	}
+	for k, v := range expected {
+		if m[k] != v {
+			t.Errorf("Test %s expected: %v actual: %v", k, v, m[k])
+		}
+	}
+	if _, ok := m["timestamp"].(float64); !ok {
+		t.Errorf("Test expected numeric timestamp actual: %v", m["timestamp"])
+	}
+}
+
+func TestGELFUDPChunked(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	s := logger.NewGELFSink("udp", pc.LocalAddr().String())
+	s.Compression = logger.GELFNone
+	s.ChunkSize = 100
+	log := newSinkLogger("billing", 4, s)
+	defer log.Close()
+	msg := strings.Repeat("x", 300)
+	log.Info(msg)
+
+	chunks := map[byte][]byte{}
+	var count byte
+	buf := make([]byte, 2048)
+	for len(chunks) == 0 || len(chunks) < int(count) {
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > 100 || buf[0] != 0x1e || buf[1] != 0x0f {
+			t.Fatalf("Test expected chunk actual: %q", buf[:n])
+		}
+		count = buf[11]
+		chunks[buf[10]] = append([]byte(nil), buf[12:n]...)
+	}
+	var seqs []int
+	for seq := range chunks {
+		seqs = append(seqs, int(seq))
+	}
+	sort.Ints(seqs)
+	var whole []byte
+	for _, seq := range seqs {
+		whole = append(whole, chunks[byte(seq)]...)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(whole, &m); err != nil {
+		t.Fatal(err, string(whole))
+	}
+	if m["short_message"] != msg {
+		t.Errorf("Test expected: %v actual: %v", msg, m["short_message"])
+	}
+}
+
+func TestGELFChunkSizeTooSmall(t *testing.T) {
+	for _, size := range []int{1, 12} {
+		s := logger.NewGELFSink("udp", "127.0.0.1:9")
+		s.Compression = logger.GELFNone
+		s.ChunkSize = size
+		if err := s.Write(&logger.Entry{Level: "INFO", Message: "hello"}); err == nil {
+			t.Errorf("Test expected error for chunk size %d", size)
+		}
+	}
+}
+
+func TestGELFUDPCompression(t *testing.T) {
+	tests := []struct {
+		compression logger.GELFCompression
+		reader      func(io.Reader) (io.Reader, error)
+	}{
+		{logger.GELFGzip, func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{logger.GELFZlib, func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }},
+	}
+	for _, tt := range tests {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := logger.NewGELFSink("udp", pc.LocalAddr().String())
+		s.Compression = tt.compression
+		log := newSinkLogger("billing", 4, s)
+		log.Warn("compressed")
+
+		buf := make([]byte, 2048)
+		pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := tt.reader(bytes.NewReader(buf[:n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(r)
+		if !bytes.Contains(data, []byte(`"short_message":"compressed"`)) {
+			t.Errorf("Test %d expected compressed message actual: %s", tt.compression, data)
+		}
+		log.Close()
+		pc.Close()
+	}
+}
+
+func TestGELFTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	msgs := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for i := 0; i < 2; i++ {
+			m, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			msgs <- m
+		}
+	}()
+
+	log := newSinkLogger("billing", 4, logger.NewGELFSink("tcp", ln.Addr().String()))
+	defer log.Close()
+	log.Info("one")
+	log.Info("two")
+	for _, want := range []string{"one", "two"} {
+		select {
+		case m := <-msgs:
+			if !strings.HasSuffix(m, "}\x00") || !strings.Contains(m, `"short_message":"`+want+`"`) {
+				t.Errorf("Test expected: %s actual: %q", want, m)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Test expected message")
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package log
+
+// Hook is called with every entry of its levels before it is written. It
+// may change the entry, returning false drops it.
+type Hook interface {
+	Fire(e *Entry) bool
+}
+
+// HookFunc adapts a function to a Hook
+type HookFunc func(e *Entry) bool
+
+// Fire calls f
+func (f HookFunc) Fire(e *Entry) bool {
+	return f(e)
+}
+
+type hook struct {
+	hook   Hook
+	levels map[string]bool
+}
+
+// AddHook adds a hook for levels, or for every level when none are given.
+// Hooks run in the order they were added after redaction and filtering,
+// the message and fields they change or add are redacted as well.
+// Child loggers created afterwards with With share it.
+func (l *Logger) AddHook(h Hook, levels ...string) {
+	hk := hook{hook: h}
+	if len(levels) > 0 {
+		hk.levels = map[string]bool{}
+		for _, lvl := range levels {
+			hk.levels[lvl] = true
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks[:len(l.hooks):len(l.hooks)], hk)
+}
+
+// fire runs the hooks on a copy of e and returns nil when a hook dropped it
+func (l *Logger) fire(e *Entry) *Entry {
+	l.mu.Lock()
+	hooks := l.hooks
+	l.mu.Unlock()
+	if len(hooks) == 0 {
+		return e
+	}
+
+	c := *e
+	c.Fields = append([]Field(nil), e.Fields...)
+	for _, h := range hooks {
+		if h.levels != nil && !h.levels[c.Level] {
+			continue
+		}
+		if !fireHook(h.hook, &c) {
+			return nil
+		}
+	}
+	return &c
+}
+
+// fireHook calls h, a hook that panics keeps the entry
+func fireHook(h Hook, e *Entry) (keep bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			keep = true
+		}
+	}()
+	return h.Fire(e)
+}
@@ -0,0 +1,74 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestHooks(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4}
+	log.SetOutput(&b)
+
+	var order []string
+	var alerts []string
+	log.AddHook(logger.HookFunc(func(e *logger.Entry) bool {
+		order = append(order, "host")
+		e.Fields = append(e.Fields, logger.F("host", "web1"))
+		return true
+	}))
+	log.AddHook(logger.HookFunc(func(e *logger.Entry) bool {
+		panic("broken hook")
+	}))
+	log.AddHook(logger.HookFunc(func(e *logger.Entry) bool {
+		order = append(order, "drop")
+		return !strings.HasPrefix(e.Message, "health")
+	}))
+	log.AddHook(logger.HookFunc(func(e *logger.Entry) bool {
+		alerts = append(alerts, e.Message)
+		return true
+	}), "ERROR", "FATAL")
+
+	child := log.With(logger.F("req", 1))
+	if child.Info("health check") != "" {
+		t.Errorf("Test expected vetoed entry")
+	}
+	child.Info("started")
+	child.Error("failed")
+
+	expected := "INFO started req=1 host=web1\n"
+	if lines := strings.SplitAfter(b.String(), "\n"); len(lines) != 3 || lines[0][13:] != expected {
+		t.Errorf("Test expected: %q actual: %q", expected, b.String())
+	}
+	if strings.Join(order, ",") != "host,drop,host,drop,host,drop" {
+		t.Errorf("Test expected hooks in order actual: %v", order)
+	}
+	if len(alerts) != 1 || alerts[0] != "failed" {
+		t.Errorf("Test expected: [failed] actual: %v", alerts)
+	}
+	if strings.Contains(child.Info("again"), "host=web1 host=web1") {
+		t.Errorf("Test expected hook changes not kept by the logger")
+	}
+}
+
+func TestHooksRedacted(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4, Redactor: logger.NewRedactor()}
+	log.Redactor.Replace = logger.Hash
+	log.SetOutput(&b)
+	log.AddHook(logger.HookFunc(func(e *logger.Entry) bool {
+		e.Message += " for jane.doe@example.com"
+		e.Fields = append(e.Fields, logger.F("token", "abc123"))
+		return true
+	}))
+
+	log.With(logger.F("password", "hunter2")).Info("login")
+
+	expected := "INFO login for [REDACTED] password=" + logger.Hash("hunter2") + " token=" + logger.Hash("abc123") + "\n"
+	if actual := b.String()[13:]; actual != expected {
+		t.Errorf("Test expected: %q actual: %q", expected, actual)
+	}
+}
@@ -0,0 +1,140 @@
+package log
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// JournalSocket is the native protocol socket of systemd-journald
+const JournalSocket = "/run/systemd/journal/socket"
+
+// JournalSink is a sink that writes entries to systemd-journald using the
+// native protocol. Fields are sent upper-cased next to PRIORITY, MESSAGE,
+// SYSLOG_IDENTIFIER and the CODE_ caller fields. Entries too large for a
+// datagram are passed in a sealed memfd.
+type JournalSink struct {
+	Socket string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewJournalSink creates a sink for the default journald socket
+func NewJournalSink() *JournalSink {
+	return &JournalSink{Socket: JournalSocket}
+}
+
+// JournalAvailable reports whether the journald socket exists
+func JournalAvailable() bool {
+	_, err := os.Stat(JournalSocket)
+	return err == nil
+}
+
+// Write sends e to the journal, reconnecting once if the socket failed so
+// that a restart of journald is picked up
+func (j *JournalSink) Write(e *Entry) error {
+	msg := journalMessage(e)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if j.conn == nil {
+			conn, derr := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: j.Socket, Net: "unixgram"})
+			if err = derr; err != nil {
+				continue
+			}
+			j.conn = conn
+		}
+		_, err = j.conn.Write(msg)
+		if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+			err = sendMemfd(j.conn, msg)
+		}
+		if err == nil {
+			return nil
+		}
+		j.conn.Close()
+		j.conn = nil
+	}
+	return err
+}
+
+// Close closes the socket
+func (j *JournalSink) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.conn == nil {
+		return nil
+	}
+	err := j.conn.Close()
+	j.conn = nil
+	return err
+}
+
+func journalMessage(e *Entry) []byte {
+	severity, ok := syslogSeverity[e.Level]
+	if !ok {
+		severity = 6
+	}
+
+	var b []byte
+	b = appendJournalField(b, "PRIORITY", strconv.Itoa(severity))
+	b = appendJournalField(b, "MESSAGE", e.Message)
+	if e.Logger != "" {
+		b = appendJournalField(b, "SYSLOG_IDENTIFIER", e.Logger)
+	}
+	if e.Caller.File != "" {
+		b = appendJournalField(b, "CODE_FILE", e.Caller.File)
+		b = appendJournalField(b, "CODE_LINE", strconv.Itoa(e.Caller.Line))
+		b = appendJournalField(b, "CODE_FUNC", e.Caller.Function)
+	}
+	if len(e.Stack) > 0 {
+		b = appendJournalField(b, "STACK_TRACE", strings.TrimPrefix(formatStack(e.Stack), "\n"))
+	}
+	for _, f := range e.Fields {
+		if key := journalKey(f.Key); key != "" {
+			b = appendJournalField(b, key, fmt.Sprint(f.Value))
+		}
+	}
+	return b
+}
+
+// appendJournalField encodes KEY=value, or the binary length prefixed form
+// for values containing newlines
+func appendJournalField(b []byte, key, value string) []byte {
+	b = append(b, key...)
+	if strings.ContainsRune(value, '\n') {
+		b = append(b, '\n')
+		b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	} else {
+		b = append(b, '=')
+	}
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// journalKey upper-cases a field name and replaces characters journald does
+// not accept. Names may not start with an underscore or digit.
+func journalKey(k string) string {
+	k = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, k)
+	k = strings.TrimLeft(k, "_0123456789")
+	if len(k) > 64 {
+		k = k[:64]
+	}
+	return k
+}
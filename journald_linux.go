@@ -0,0 +1,73 @@
+//go:build linux
+
+package log
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	mfdCloexec       = 0x1
+	mfdAllowSealing  = 0x2
+	fAddSeals        = 1033
+	fSealAll         = 0x1 | 0x2 | 0x4 | 0x8
+	journalMemfdName = "journal-entry\x00"
+)
+
+// sysMemfdCreate is the memfd_create syscall number, which the syscall
+// package does not define
+var sysMemfdCreate = map[string]uintptr{
+	"386":      356,
+	"amd64":    319,
+	"arm":      385,
+	"arm64":    279,
+	"loong64":  279,
+	"mips":     4354,
+	"mipsle":   4354,
+	"mips64":   5314,
+	"mips64le": 5314,
+	"ppc64":    360,
+	"ppc64le":  360,
+	"riscv64":  279,
+	"s390x":    350,
+}
+
+// sendMemfd writes msg to a sealed memfd and passes it to journald
+func sendMemfd(conn *net.UnixConn, msg []byte) error {
+	trap, ok := sysMemfdCreate[runtime.GOARCH]
+	if !ok {
+		return os.NewSyscallError("memfd_create", syscall.ENOSYS)
+	}
+	name := []byte(journalMemfdName)
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(&name[0])), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return os.NewSyscallError("memfd_create", errno)
+	}
+	f := os.NewFile(fd, "journal-entry")
+	defer f.Close()
+
+	if _, err := f.Write(msg); err != nil {
+		return err
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, fd, fAddSeals, fSealAll); errno != 0 {
+		return os.NewSyscallError("fcntl", errno)
+	}
+	// WriteMsgUnix refuses connected datagram sockets, send on the raw fd
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var sendErr error
+	err = raw.Write(func(s uintptr) bool {
+		sendErr = syscall.Sendmsg(int(s), nil, syscall.UnixRights(int(fd)), nil, 0)
+		return sendErr != syscall.EAGAIN
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
@@ -0,0 +1,54 @@
+package log_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestJournalSinkMemfd(t *testing.T) {
+	conn, j, done := newJournal(t)
+	defer done()
+
+	log := &logger.Logger{Name: "daemon", Level: 4}
+	log.SetOutput(&bytes.Buffer{})
+	log.AddSink(j)
+	big := strings.Repeat("x", 4<<20)
+	log.Info(big)
+
+	buf := make([]byte, 16)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("expected a control message: %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("expected a file descriptor: %v", err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "memfd")
+	defer f.Close()
+
+	f.Seek(0, io.SeekStart)
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "MESSAGE="+big+"\n") {
+		t.Errorf("expected message in memfd, got %d bytes", len(data))
+	}
+	if _, err := f.Write([]byte("x")); err == nil {
+		t.Errorf("expected memfd to be sealed")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package log
+
+import (
+	"errors"
+	"net"
+)
+
+// sendMemfd is only supported on linux, where journald runs
+func sendMemfd(conn *net.UnixConn, msg []byte) error {
+	return errors.New("log: journal entry too large")
+}
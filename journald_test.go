@@ -0,0 +1,105 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func newJournal(t *testing.T) (*net.UnixConn, *logger.JournalSink, func()) {
+	dir, err := os.MkdirTemp("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Skip(err)
+	}
+	conn.SetReadBuffer(1 << 20)
+	j := logger.NewJournalSink()
+	j.Socket = addr
+	return conn, j, func() {
+		j.Close()
+		conn.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestJournalSink(t *testing.T) {
+	conn, j, done := newJournal(t)
+	defer done()
+
+	log := &logger.Logger{Name: "daemon", Level: 4, Function: true}
+	log.SetOutput(&bytes.Buffer{})
+	log.AddSink(j)
+	log.With(logger.F("request-id", "r1"), logger.F("_hidden", 1), logger.F("body", "a\nb")).Warn("disk full")
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+
+	for _, s := range []string{
+		"PRIORITY=4\n",
+		"MESSAGE=disk full\n",
+		"SYSLOG_IDENTIFIER=daemon\n",
+		"CODE_FILE=",
+		"journald_test.go\n",
+		"CODE_FUNC=github.com/casonadams/simple-logger_test.TestJournalSink\n",
+		"REQUEST_ID=r1\n",
+		"HIDDEN=1\n",
+	} {
+		if !strings.Contains(msg, s) {
+			t.Errorf("expected %q in %q", s, msg)
+		}
+	}
+
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, 3)
+	if !strings.HasSuffix(msg, "BODY\n"+string(size)+"a\nb\n") {
+		t.Errorf("expected binary encoded BODY in %q", msg)
+	}
+}
+
+func TestJournalSinkReconnect(t *testing.T) {
+	conn, j, done := newJournal(t)
+	defer done()
+
+	if err := j.Write(&logger.Entry{Level: "INFO", Message: "first"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// restart journald: the socket is removed and created again
+	conn.Close()
+	os.Remove(j.Socket)
+	if err := j.Write(&logger.Entry{Level: "INFO", Message: "lost"}); err == nil {
+		t.Errorf("expected error while journald is down")
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: j.Socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := j.Write(&logger.Entry{Level: "INFO", Message: "second"}); err != nil {
+		t.Fatalf("expected reconnect after restart, actual %v", err)
+	}
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil || !strings.Contains(string(buf[:n]), "MESSAGE=second\n") {
+		t.Errorf("expected entry after restart, actual %q %v", buf[:n], err)
+	}
+}
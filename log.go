@@ -2,12 +2,9 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
-	"runtime"
-	"strings"
 	"sync"
-	"time"
 )
 
 var level map[string]int = map[string]int{
@@ -19,231 +16,299 @@ var level map[string]int = map[string]int{
 	"FATAL": 1,
 }
 
-type color int
-
-// Colors
-const (
-	DMAGENTA color = 35
-	GRAY     color = 90
-	RED      color = 91
-	YELLOW   color = 93
-	BLUE     color = 94
-	MAGENTA  color = 95
-	CYAN     color = 96
-)
-
 // Logger struct
 type Logger struct {
 	mu       sync.Mutex
+	out      io.Writer
+	sinks    []Sink
+	hooks    []hook
+	fields   []Field
+	problems []error
+	Name     string
 	Level    int
 	Date     bool
-	Color    bool
-	Function bool
-	UTC      bool
+	// Color is the decision of ColorAuto for the output, SetOutput and
+	// SetColorMode update it. ColorAlways and ColorNever override it.
+	Color     bool
+	ColorMode ColorMode
+	Theme     *Theme
+	Function  bool
+	// CallerSkip is the number of extra stack frames to skip when finding
+	// the caller, for wrappers around Logger
+	CallerSkip int
+	CallerPath PathMode
+	CallerFunc bool
+	UTC        bool
+	Format     Format
+	Sanitize   SanitizeMode
+	// Stack attaches a stack trace to entries at or above StackLevel
+	Stack      bool
+	StackLevel int
+	// Extractors add fields from the context to entries logged with the
+	// Ctx methods
+	Extractors []ContextExtractor
+	// ErrorHandler is called when writing to the output or a sink fails,
+	// by default a rate limited message is written to stderr
+	ErrorHandler func(err *SinkError)
+	// Fallback receives entries that failed to be written elsewhere
+	Fallback Sink
+	// Sampler drops repeated entries, it is shared with child loggers
+	Sampler *Sampler
+	// RateLimiter caps the entries written, it is shared with child loggers
+	RateLimiter *RateLimiter
+	// Dedup collapses repeated entries, it is shared with child loggers
+	Dedup *Dedup
+	// Redactor removes sensitive data from entries before they are written
+	Redactor *Redactor
+	// Metrics counts entries, bytes and errors, it is shared with child
+	// loggers
+	Metrics *Metrics
+
+	sinkCounters *sinkCounters
+	// tree is the configuration of a logger created by Loggers and gen the
+	// reload last applied to it
+	tree *Loggers
+	gen  uint64
+	// owner is the logger a copy made by live was taken from, its lock
+	// serializes the writes to the output
+	owner *Logger
 }
 
-// NewLogger creates a new logger
+// NewLogger creates a new logger configured from the LOG_ environment
+// variables
 func NewLogger(name string) *Logger {
-	envLevel := strings.ToUpper(os.Getenv("LOG_LEVEL"))
-	envDate := strings.ToLower(os.Getenv("LOG_DATE"))
-	envColor := strings.ToLower(os.Getenv("LOG_COLOR"))
-	envFunc := strings.ToLower(os.Getenv("LOG_FUNC"))
-	envUTC := strings.ToLower(os.Getenv("LOG_UTC"))
+	return New(WithName(name), FromEnv(""))
+}
 
-	var logLevel int = 4
-	if len(envLevel) > 0 {
-		logLevel = level[envLevel]
-	}
+// SetOutput sets the output destination for the logger. When ColorMode is
+// ColorAuto the color decision is re-evaluated against the new writer.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.update()
+	l.out = w
+	l.Color = l.ColorMode.enabled(w)
+}
 
-	var date bool = true
-	if envDate == "false" || envDate == "0" {
-		date = false
-	}
-	var lcolor bool = true
-	if envColor == "false" || envColor == "0" {
-		lcolor = false
-	}
-	var showFunc bool = true
-	if envFunc == "false" || envFunc == "0" {
-		showFunc = false
-	}
-	var tzUTC bool = true
-	if envUTC == "false" || envUTC == "0" {
-		tzUTC = false
-	}
+// SetColorMode sets the color mode and re-evaluates Color for the current
+// output.
+func (l *Logger) SetColorMode(m ColorMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.update()
+	l.ColorMode = m
+	l.Color = m.enabled(l.writer())
+}
 
-	return &Logger{
-		Level:    logLevel,
-		Date:     date,
-		Color:    lcolor,
-		Function: showFunc,
-		UTC:      tzUTC,
+func (l *Logger) writer() io.Writer {
+	if l.out == nil {
+		return os.Stdout
 	}
+	return l.out
 }
 
-func (l *Logger) color(m string, c color) string {
-	if l.Color {
-		return fmt.Sprintf("\033[%dm%s\033[0m", c, m)
+func (l *Logger) write(s string) (int, error) {
+	mu := &l.mu
+	if l.owner != nil {
+		mu = &l.owner.mu
 	}
-	return m
+	mu.Lock()
+	defer mu.Unlock()
+	return fmt.Fprintln(l.writer(), s)
 }
 
-func (l *Logger) format(logLevel string, msg string) string {
-	prefix := ""
+// log redacts e and writes it unless it is dropped by sampling, rate
+// limiting or deduplication and returns the formatted line
+func (l *Logger) log(e *Entry) string {
+	e = l.redact(e)
+	if !l.filter(e) {
+		l.Metrics.suppress(e)
+		return ""
+	}
+	return l.emit(e)
+}
 
-	// Setup timesampe
-	if l.UTC {
-		if l.Date {
-			prefix += fmt.Sprintf("%v ", time.Now().UTC().Format("2006-01-02 15:04:05.000"))
-		} else {
-			prefix += fmt.Sprintf("%v ", time.Now().UTC().Format("15:04:05.000"))
+// filter reports whether e passes sampling, rate limiting and
+// deduplication, writing the summaries they return. FATAL entries are
+// always written.
+func (l *Logger) filter(e *Entry) bool {
+	if e.Level == "FATAL" {
+		return true
+	}
+	if l.Sampler != nil {
+		keep, summaries := l.Sampler.sample(l, e)
+		for _, s := range summaries {
+			l.emit(l.summary(s))
 		}
-	} else {
-		if l.Date {
-			prefix += fmt.Sprintf("%v ", time.Now().Format("2006-01-02 15:04:05.000"))
-		} else {
-			prefix += fmt.Sprintf("%v ", time.Now().Format("15:04:05.000"))
+		if !keep {
+			return false
 		}
 	}
-
-	// Logging level
-	prefix += logLevel + " "
-
-	// Caller location
-	if l.Function {
-		_, file, line, _ := runtime.Caller(2)
-		prefix += fmt.Sprintf("[%v:%v] ", filepath.Base(file), line)
+	if l.RateLimiter != nil {
+		keep, summaries := l.RateLimiter.allow(l, e)
+		for _, s := range summaries {
+			l.emit(l.summary(s))
+		}
+		if !keep {
+			return false
+		}
 	}
+	return l.Dedup == nil || l.Dedup.check(l, e)
+}
 
-	return (prefix + msg)
+// emit runs the hooks, writes e to the output and the sinks and returns the
+// formatted line. Write errors are passed to the error handler.
+func (l *Logger) emit(e *Entry) string {
+	f := l.fire(e)
+	if f == nil {
+		l.Metrics.suppress(e)
+		return ""
+	}
+	if f != e && l.Redactor != nil {
+		f = l.Redactor.redactHooked(e, f)
+	}
+	e = f
+	s := l.format(e)
+	n, err := l.write(s)
+	if err != nil {
+		l.sinkError(nil, e, err)
+	}
+	l.mu.Lock()
+	sinks := l.sinks
+	l.mu.Unlock()
+	for _, sink := range sinks {
+		if c, ok := sink.(*configSinks); ok {
+			c.write(e, func(s Sink, err error) { l.sinkError(s, e, err) })
+			continue
+		}
+		if err := writeSink(sink, e); err != nil {
+			l.sinkError(sink, e, err)
+		}
+	}
+	l.Metrics.emit(e, n)
+	return s
 }
 
 // Debug logs debug messages
 func (l *Logger) Debug(msg string) string {
+	l = l.live()
 	if l.Level >= level["DEBUG"] {
-		s := l.format(l.color("DEBUG", GRAY), msg)
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("DEBUG", msg))
 	}
 	return ""
 }
 
 // Debugf logs debug messages
 func (l *Logger) Debugf(format string, args ...interface{}) string {
+	l = l.live()
 	if l.Level >= level["DEBUG"] {
-		s := l.format(l.color("DEBUG", GRAY), fmt.Sprintf(format, args...))
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("DEBUG", fmt.Sprintf(format, args...)).withTemplate(format))
 	}
 	return ""
 }
 
 // Trace logs trace messages
 func (l *Logger) Trace(msg string) string {
+	l = l.live()
 	if l.Level >= level["TRACE"] {
-		s := l.format(l.color("TRACE", CYAN), msg)
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("TRACE", msg))
 	}
 	return ""
 }
 
 // Tracef logs trace messages
 func (l *Logger) Tracef(format string, args ...interface{}) string {
+	l = l.live()
 	if l.Level >= level["TRACE"] {
-		s := l.format(l.color("TRACE", CYAN), fmt.Sprintf(format, args...))
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("TRACE", fmt.Sprintf(format, args...)).withTemplate(format))
 	}
 	return ""
 }
 
 // Info logs info messages
 func (l *Logger) Info(msg string) string {
+	l = l.live()
 	if l.Level >= level["INFO"] {
-		s := l.format(l.color("INFO", BLUE), msg)
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("INFO", msg))
 	}
 	return ""
 }
 
 // Infof logs imfo messages
 func (l *Logger) Infof(format string, args ...interface{}) string {
+	l = l.live()
 	if l.Level >= level["INFO"] {
-		s := l.format(l.color("INFO", BLUE), fmt.Sprintf(format, args...))
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("INFO", fmt.Sprintf(format, args...)).withTemplate(format))
 	}
 	return ""
 }
 
 // Warn logs warn messages
 func (l *Logger) Warn(msg string) string {
+	l = l.live()
 	if l.Level >= level["WARN"] {
-		s := l.format(l.color("WARN", YELLOW), msg)
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("WARN", msg))
 	}
 	return ""
 }
 
 // Warnf logs wann messages
 func (l *Logger) Warnf(format string, args ...interface{}) string {
+	l = l.live()
 	if l.Level >= level["WARN"] {
-		s := l.format(l.color("WARN", YELLOW), fmt.Sprintf(format, args...))
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("WARN", fmt.Sprintf(format, args...)).withTemplate(format))
 	}
 	return ""
 }
 
 // Error logs error messages
 func (l *Logger) Error(msg string) string {
+	l = l.live()
 	if l.Level >= level["ERROR"] {
-		s := l.format(l.color("ERROR", RED), msg)
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("ERROR", msg))
 	}
 	return ""
 }
 
 // Errorf logs error messages
 func (l *Logger) Errorf(format string, args ...interface{}) string {
+	l = l.live()
 	if l.Level >= level["ERROR"] {
-		s := l.format(l.color("ERROR", RED), fmt.Sprintf(format, args...))
-		fmt.Println(s)
-		return s
+		return l.log(l.newEntry("ERROR", fmt.Sprintf(format, args...)).withTemplate(format))
 	}
 	return ""
 }
 
 // Fatal logs fatal message and exits (1)
 func (l *Logger) Fatal(msg string) string {
-	s := l.format(l.color("FATAL", MAGENTA), msg)
-	fmt.Println(s)
+	l = l.live()
+	s := l.log(l.newEntry("FATAL", msg))
+	l.Close()
 	defer os.Exit(1)
 	return s
 }
 
 // Fatalf logs fatal message and exits (1)
 func (l *Logger) Fatalf(format string, args ...interface{}) string {
-	s := l.format(l.color("FATAL", MAGENTA), fmt.Sprintf(format, args...))
-	fmt.Println(s)
+	l = l.live()
+	s := l.log(l.newEntry("FATAL", fmt.Sprintf(format, args...)).withTemplate(format))
+	l.Close()
 	defer os.Exit(1)
 	return s
 }
 
 // Panic logs fatal message and exits (1)
 func (l *Logger) Panic(msg string) string {
-	s := l.format(l.color("PANIC", DMAGENTA), msg)
+	l = l.live()
+	s := l.format(l.redact(l.newEntry("PANIC", msg)))
 	defer panic(s)
 	return s
 }
 
 // Panicf logs fatal message and exits (1)
 func (l *Logger) Panicf(format string, args ...interface{}) string {
-	s := l.format(l.color("PANIC", DMAGENTA), fmt.Sprintf(format, args...))
+	l = l.live()
+	s := l.format(l.redact(l.newEntry("PANIC", fmt.Sprintf(format, args...)).withTemplate(format)))
 	defer panic(s)
 	return s
 }
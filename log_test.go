@@ -11,11 +11,11 @@ import (
 func TestNewLogger(t *testing.T) {
 	actual := logger.NewLogger("test")
 	expected := &logger.Logger{
-		Level:    4,
-		Date:     true,
-		Color:    true,
-		Function: true,
-		UTC:      true,
+		Level:     4,
+		Date:      true,
+		ColorMode: logger.ColorAuto,
+		Function:  true,
+		UTC:       true,
 	}
 
 	if expected.Level != actual.Level {
@@ -24,8 +24,8 @@ func TestNewLogger(t *testing.T) {
 	if expected.Date != actual.Date {
 		t.Errorf("expected %v, actual %v", expected.Date, actual.Date)
 	}
-	if expected.Color != actual.Color {
-		t.Errorf("expected %v, actual %v", expected.Color, actual.Color)
+	if expected.ColorMode != actual.ColorMode {
+		t.Errorf("expected %v, actual %v", expected.ColorMode, actual.ColorMode)
 	}
 	if expected.Function != actual.Function {
 		t.Errorf("expected %v, actual %v", expected.Function, actual.Function)
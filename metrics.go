@@ -0,0 +1,171 @@
+package log
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Metrics counts the entries written and suppressed per logger name and
+// level, the bytes written to the output and the sink errors. Suppressed
+// entries are those dropped by sampling, rate limiting, deduplication or a
+// hook. A Metrics can be shared by several loggers and serves the counters
+// in the Prometheus text format.
+type Metrics struct {
+	mu         sync.Mutex
+	counts     map[metricKey]*metricCount
+	bytes      uint64
+	sinkErrors uint64
+}
+
+type metricKey struct {
+	logger string
+	level  string
+}
+
+type metricCount struct {
+	emitted    uint64
+	suppressed uint64
+}
+
+// Counts are the entries written and suppressed by level
+type Counts struct {
+	Emitted    map[string]uint64
+	Suppressed map[string]uint64
+}
+
+// Stats is a snapshot of Metrics
+type Stats struct {
+	Counts
+	// Loggers holds the counts per logger name
+	Loggers      map[string]Counts
+	BytesWritten uint64
+	SinkErrors   uint64
+}
+
+// NewMetrics creates an empty Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{counts: map[metricKey]*metricCount{}}
+}
+
+func (m *Metrics) count(e *Entry) *metricCount {
+	if m.counts == nil {
+		m.counts = map[metricKey]*metricCount{}
+	}
+	k := metricKey{logger: e.Logger, level: e.Level}
+	c, ok := m.counts[k]
+	if !ok {
+		c = &metricCount{}
+		m.counts[k] = c
+	}
+	return c
+}
+
+func (m *Metrics) emit(e *Entry, n int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.count(e).emitted++
+	m.bytes += uint64(n)
+}
+
+func (m *Metrics) suppress(e *Entry) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.count(e).suppressed++
+}
+
+func (m *Metrics) sinkError() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sinkErrors++
+}
+
+// Stats returns a snapshot of the counters
+func (m *Metrics) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := Stats{
+		Counts:       newCounts(),
+		Loggers:      map[string]Counts{},
+		BytesWritten: m.bytes,
+		SinkErrors:   m.sinkErrors,
+	}
+	for k, c := range m.counts {
+		byName, ok := s.Loggers[k.logger]
+		if !ok {
+			byName = newCounts()
+			s.Loggers[k.logger] = byName
+		}
+		byName.Emitted[k.level] += c.emitted
+		byName.Suppressed[k.level] += c.suppressed
+		s.Emitted[k.level] += c.emitted
+		s.Suppressed[k.level] += c.suppressed
+	}
+	return s
+}
+
+func newCounts() Counts {
+	return Counts{Emitted: map[string]uint64{}, Suppressed: map[string]uint64{}}
+}
+
+// Publish publishes the stats as an expvar variable. Like expvar.Publish it
+// panics when name is already in use.
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return m.Stats()
+	}))
+}
+
+// ServeHTTP writes the counters in the Prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	keys := make([]metricKey, 0, len(m.counts))
+	for k := range m.counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].logger != keys[j].logger {
+			return keys[i].logger < keys[j].logger
+		}
+		return level[keys[i].level] > level[keys[j].level]
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP log_entries_total Entries written by logger and level.\n")
+	b.WriteString("# TYPE log_entries_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "log_entries_total{logger=%s,level=%s} %d\n", label(k.logger), label(k.level), m.counts[k].emitted)
+	}
+	b.WriteString("# HELP log_entries_suppressed_total Entries dropped by sampling, rate limiting, deduplication or hooks.\n")
+	b.WriteString("# TYPE log_entries_suppressed_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "log_entries_suppressed_total{logger=%s,level=%s} %d\n", label(k.logger), label(k.level), m.counts[k].suppressed)
+	}
+	b.WriteString("# HELP log_bytes_written_total Bytes written to the logger output.\n")
+	b.WriteString("# TYPE log_bytes_written_total counter\n")
+	fmt.Fprintf(&b, "log_bytes_written_total %d\n", m.bytes)
+	b.WriteString("# HELP log_sink_errors_total Failed writes to the output or a sink.\n")
+	b.WriteString("# TYPE log_sink_errors_total counter\n")
+	fmt.Fprintf(&b, "log_sink_errors_total %d\n", m.sinkErrors)
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
+
+// label quotes a Prometheus label value
+func label(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+}
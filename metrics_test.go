@@ -0,0 +1,82 @@
+package log_test
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+var publishRuns int
+
+func TestMetrics(t *testing.T) {
+	var b bytes.Buffer
+	m := logger.NewMetrics()
+	api := &logger.Logger{Name: "api", Level: 4, Metrics: m, Dedup: logger.NewDedup(time.Hour)}
+	api.SetOutput(&b)
+	db := &logger.Logger{Name: "db", Level: 4, Metrics: m, ErrorHandler: func(*logger.SinkError) {}}
+	db.SetOutput(failWriter{})
+
+	api.Info("one")
+	api.Info("two")
+	api.Error("failed")
+	api.Error("failed")
+	api.With(logger.F("k", "v")).Warn("child")
+	db.Error("lost")
+
+	s := m.Stats()
+	// the repeated error is suppressed and reported by a dedup summary
+	if s.Emitted["INFO"] != 2 || s.Emitted["ERROR"] != 3 || s.Suppressed["ERROR"] != 1 {
+		t.Errorf("Test expected per level counts actual: %+v", s.Counts)
+	}
+	if s.Loggers["api"].Emitted["ERROR"] != 2 || s.Loggers["db"].Emitted["ERROR"] != 1 || s.Loggers["api"].Emitted["WARN"] != 1 {
+		t.Errorf("Test expected per logger counts actual: %+v", s.Loggers)
+	}
+	if s.BytesWritten != uint64(b.Len()) {
+		t.Errorf("Test expected: %d bytes actual: %d", b.Len(), s.BytesWritten)
+	}
+	if s.SinkErrors != 1 {
+		t.Errorf("Test expected: 1 sink error actual: %d", s.SinkErrors)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	m := logger.NewMetrics()
+	log := &logger.Logger{Name: `a"b`, Level: 4, Metrics: m}
+	log.SetOutput(io.Discard)
+	log.Info("one")
+	log.AddHook(logger.HookFunc(func(e *logger.Entry) bool { return false }))
+	log.Warn("vetoed")
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE log_entries_total counter",
+		`log_entries_total{logger="a\"b",level="INFO"} 1`,
+		`log_entries_suppressed_total{logger="a\"b",level="WARN"} 1`,
+		"log_bytes_written_total 22",
+		"log_sink_errors_total 0",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Test expected: %s actual: %s", line, body)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Test expected text content type actual: %s", ct)
+	}
+
+	// expvar names cannot be published twice, e.g. with go test -count=2
+	publishRuns++
+	name := fmt.Sprintf("log_test_metrics_%d", publishRuns)
+	m.Publish(name)
+	if v := expvar.Get(name).String(); !strings.Contains(v, `"INFO":1`) {
+		t.Errorf("Test expected expvar stats actual: %s", v)
+	}
+}
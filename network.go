@@ -0,0 +1,278 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NetworkStats counts the entries handled by a network sink
+type NetworkStats struct {
+	Sent    uint64
+	Retried uint64
+	Dropped uint64
+	Spooled int
+}
+
+// NetworkSink is a sink that ships encoded entries to a log collector over
+// tcp, udp or http. Entries are queued in a spool and delivered in the
+// background, so logging never waits on the network. Failed deliveries are
+// retried with exponential backoff while the spool keeps the entries; when
+// the spool is full the oldest entries are dropped.
+//
+// tcp sends newline terminated lines, udp one entry per datagram and http
+// POSTs batches of up to BatchSize newline-delimited entries to the URL in
+// Addr, gzip compressed when Gzip is set. Timeout limits every dial, write
+// and request. An unset Spool, BatchSize or Backoff takes the default of
+// NewNetworkSink.
+type NetworkSink struct {
+	Network    string
+	Addr       string
+	Format     Format
+	Spool      Spool
+	BatchSize  int
+	Gzip       bool
+	Headers    map[string]string
+	Timeout    time.Duration
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Client     *http.Client
+
+	once    sync.Once
+	mu      sync.Mutex
+	conn    net.Conn
+	closed  bool
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+
+	sent    uint64
+	retried uint64
+	dropped uint64
+}
+
+// NewNetworkSink creates a sink sending JSON lines, spooling up to 10000
+// entries in memory
+func NewNetworkSink(network, addr string) *NetworkSink {
+	return &NetworkSink{
+		Network:    network,
+		Addr:       addr,
+		Format:     JSONFormat,
+		Spool:      NewMemorySpool(10000),
+		BatchSize:  100,
+		Timeout:    5 * time.Second,
+		Backoff:    100 * time.Millisecond,
+		MaxBackoff: 30 * time.Second,
+		Client:     http.DefaultClient,
+	}
+}
+
+// Stats returns the delivery counters of the sink
+func (s *NetworkSink) Stats() NetworkStats {
+	s.start()
+	return NetworkStats{
+		Sent:    atomic.LoadUint64(&s.sent),
+		Retried: atomic.LoadUint64(&s.retried),
+		Dropped: atomic.LoadUint64(&s.dropped),
+		Spooled: s.Spool.Len(),
+	}
+}
+
+// start fills in the unset defaults and starts the delivery goroutine
+func (s *NetworkSink) start() {
+	s.once.Do(func() {
+		if s.Spool == nil {
+			s.Spool = NewMemorySpool(10000)
+		}
+		s.wake = make(chan struct{}, 1)
+		s.done = make(chan struct{})
+		s.stopped = make(chan struct{})
+		go s.run()
+	})
+}
+
+// Write encodes e and queues it for delivery
+func (s *NetworkSink) Write(e *Entry) error {
+	s.start()
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return errClosed
+	}
+
+	enc := &Logger{Format: s.Format, Function: true, Date: true}
+	dropped, err := s.Spool.Push([]byte(enc.format(e)))
+	atomic.AddUint64(&s.dropped, uint64(dropped))
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// Close tries to deliver the spooled entries once and stops the sink.
+// Entries left in a memory spool are counted as dropped.
+func (s *NetworkSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.start()
+	close(s.done)
+	<-s.stopped
+
+	err := s.deliver()
+	if _, ok := s.Spool.(*MemorySpool); ok {
+		atomic.AddUint64(&s.dropped, uint64(s.Spool.Len()))
+	}
+	s.mu.Lock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *NetworkSink) run() {
+	defer close(s.stopped)
+	initial := s.Backoff
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	backoff := initial
+	for {
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+		for s.Spool.Len() > 0 {
+			if err := s.deliver(); err == nil {
+				backoff = initial
+				continue
+			}
+			select {
+			case <-time.After(backoff):
+			case <-s.done:
+				return
+			}
+			if backoff *= 2; s.MaxBackoff > 0 && backoff > s.MaxBackoff {
+				backoff = s.MaxBackoff
+			}
+		}
+	}
+}
+
+// deliver sends the spooled entries until the spool is empty or a send fails
+func (s *NetworkSink) deliver() error {
+	n := 1
+	if s.Network == "http" || s.Network == "https" {
+		if n = s.BatchSize; n <= 0 {
+			n = 100
+		}
+	}
+	for s.Spool.Len() > 0 {
+		batch, err := s.Spool.Peek(n)
+		if err != nil {
+			return err
+		}
+		if err := s.send(batch); err != nil {
+			atomic.AddUint64(&s.retried, uint64(len(batch)))
+			return err
+		}
+		if err := s.Spool.Pop(len(batch)); err != nil {
+			return err
+		}
+		atomic.AddUint64(&s.sent, uint64(len(batch)))
+	}
+	return nil
+}
+
+func (s *NetworkSink) send(batch [][]byte) error {
+	if s.Network == "http" || s.Network == "https" {
+		return s.post(batch)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.Network, s.Addr, s.Timeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	for _, b := range batch {
+		if s.Network != "udp" {
+			b = append(b[:len(b):len(b)], '\n')
+		}
+		if s.Timeout > 0 {
+			s.conn.SetWriteDeadline(time.Now().Add(s.Timeout))
+		}
+		if _, err := s.conn.Write(b); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *NetworkSink) post(batch [][]byte) error {
+	var body bytes.Buffer
+	if s.Gzip {
+		zw := gzip.NewWriter(&body)
+		for _, b := range batch {
+			zw.Write(b)
+			zw.Write([]byte("\n"))
+		}
+		zw.Close()
+	} else {
+		for _, b := range batch {
+			body.Write(b)
+			body.WriteByte('\n')
+		}
+	}
+
+	ctx := context.Background()
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Addr, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError(resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package log_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func readLines(t *testing.T, ln net.Listener, n int) []string {
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	r := bufio.NewReader(conn)
+	var lines []string
+	for i := 0; i < n; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestNetworkSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	s := logger.NewNetworkSink("tcp", ln.Addr().String())
+	log := newSinkLogger("api", 4, s)
+	log.With(logger.F("id", 1)).Info("one")
+	log.Warn("two")
+
+	lines := readLines(t, ln, 2)
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["msg"] != "one" || m["id"] != 1.0 || m["level"] != "INFO" {
+		t.Errorf("unexpected entry %v", lines[0])
+	}
+	if !strings.Contains(lines[1], `"msg":"two"`) {
+		t.Errorf("unexpected entry %v", lines[1])
+	}
+	log.Close()
+	if st := s.Stats(); st.Sent != 2 || st.Dropped != 0 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
+
+func TestNetworkSinkRetry(t *testing.T) {
+	// reserve a port and close it so the first deliveries fail
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	s := logger.NewNetworkSink("tcp", addr)
+	s.Backoff = 5 * time.Millisecond
+	s.MaxBackoff = 20 * time.Millisecond
+	log := newSinkLogger("api", 4, s)
+	log.Info("spooled")
+
+	time.Sleep(30 * time.Millisecond)
+	if st := s.Stats(); st.Retried == 0 || st.Spooled != 1 {
+		t.Errorf("expected retries while down %+v", st)
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	lines := readLines(t, ln, 1)
+	if !strings.Contains(lines[0], `"msg":"spooled"`) {
+		t.Errorf("unexpected entry %v", lines[0])
+	}
+	log.Close()
+}
+
+func TestNetworkSinkUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	s := logger.NewNetworkSink("udp", pc.LocalAddr().String())
+	s.Format = logger.TextFormat
+	log := newSinkLogger("api", 4, s)
+	defer log.Close()
+	log.Info("datagram")
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(buf[:n]), "INFO datagram") {
+		t.Errorf("unexpected datagram %q", buf[:n])
+	}
+}
+
+func TestNetworkSinkHTTP(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := io.ReadAll(zr)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	s := logger.NewNetworkSink("http", srv.URL)
+	s.Gzip = true
+	s.BatchSize = 3
+	log := newSinkLogger("api", 4, s)
+	for i := 0; i < 5; i++ {
+		log.Infof("entry %d", i)
+	}
+	log.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	all := strings.Join(bodies, "")
+	if strings.Count(all, "\n") != 5 || !strings.Contains(all, `"msg":"entry 4"`) {
+		t.Errorf("unexpected bodies %q", bodies)
+	}
+	for _, b := range bodies {
+		if strings.Count(b, "\n") > 3 {
+			t.Errorf("batch larger than BatchSize %q", b)
+		}
+	}
+	if st := s.Stats(); st.Sent != 5 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
+
+func TestNetworkSinkLiteral(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	s := &logger.NetworkSink{Network: "http", Addr: srv.URL}
+	log := newSinkLogger("api", 4, s)
+	for i := 0; i < 3; i++ {
+		log.Info("entry")
+	}
+	time.Sleep(50 * time.Millisecond)
+	log.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if st := s.Stats(); st.Sent != 3 || requests > 3 {
+		t.Errorf("unexpected stats %+v after %d requests", st, requests)
+	}
+}
+
+func TestNetworkSinkHTTPTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	s := logger.NewNetworkSink("http", srv.URL)
+	s.Timeout = 50 * time.Millisecond
+	s.Backoff = 10 * time.Millisecond
+	s.Write(&logger.Entry{Level: "INFO", Message: "hello"})
+
+	done := make(chan error, 1)
+	go func() { done <- s.Close() }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected timeout error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on a server that never answers")
+	}
+}
+
+func TestMemorySpool(t *testing.T) {
+	s := logger.NewMemorySpool(2)
+	s.Push([]byte("a"))
+	s.Push([]byte("b"))
+	if dropped, _ := s.Push([]byte("c")); dropped != 1 {
+		t.Errorf("expected oldest to be dropped, actual %d", dropped)
+	}
+	items, _ := s.Peek(5)
+	if len(items) != 2 || string(items[0]) != "b" || string(items[1]) != "c" {
+		t.Errorf("unexpected items %q", items)
+	}
+	s.Pop(1)
+	if s.Len() != 1 {
+		t.Errorf("unexpected length %d", s.Len())
+	}
+}
+
+func TestDiskSpool(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spool")
+
+	s, err := logger.OpenDiskSpool(path, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"first", "second", "third"} {
+		s.Push([]byte(v))
+	}
+	s.Pop(1)
+	s.Close()
+
+	s, err = logger.OpenDiskSpool(path, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	items, err := s.Peek(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 1 || len(items) != 1 || string(items[0]) != "third" {
+		t.Errorf("unexpected items %q", items)
+	}
+	s.Pop(1)
+	if fi, _ := os.Stat(path); s.Len() != 0 || fi.Size() != 8 {
+		t.Errorf("expected empty spool to be truncated")
+	}
+}
+
+func TestDiskSpoolCompact(t *testing.T) {
+	dir, err := os.MkdirTemp("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spool")
+
+	s, err := logger.OpenDiskSpool(path, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := make([]byte, 100)
+	for i := 0; i < 10000; i++ {
+		entry[0] = byte(i)
+		if _, err := s.Push(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fi, _ := os.Stat(path); fi.Size() > 8+2*(1024+104) {
+		t.Errorf("expected spool file to be compacted actual size %d", fi.Size())
+	}
+	n := s.Len()
+	s.Close()
+
+	s, err = logger.OpenDiskSpool(path, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	items, _ := s.Peek(n + 1)
+	if s.Len() != n || len(items) != n || items[n-1][0] != byte(9999%256) {
+		t.Errorf("unexpected items after compaction %d %d", s.Len(), len(items))
+	}
+}
@@ -0,0 +1,238 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Option configures a logger created by New
+type Option func(l *Logger)
+
+// New creates a logger at INFO with dates, caller, UTC times and automatic
+// color written to stdout, changed by opts in order. Unlike NewLogger it
+// does not read the environment unless FromEnv is given.
+func New(opts ...Option) *Logger {
+	l := &Logger{
+		Level:      level["INFO"],
+		Date:       true,
+		ColorMode:  ColorAuto,
+		Theme:      DefaultTheme,
+		Function:   true,
+		UTC:        true,
+		StackLevel: level["ERROR"],
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.SetOutput(l.writer())
+	l.warnProblems()
+	return l
+}
+
+// WithName sets the logger name
+func WithName(name string) Option {
+	return func(l *Logger) { l.Name = name }
+}
+
+// WithLevel sets the level
+func WithLevel(lvl int) Option {
+	return func(l *Logger) { l.Level = lvl }
+}
+
+// WithDate shows or hides the date
+func WithDate(date bool) Option {
+	return func(l *Logger) { l.Date = date }
+}
+
+// WithColorMode sets the color mode
+func WithColorMode(m ColorMode) Option {
+	return func(l *Logger) { l.ColorMode = m }
+}
+
+// WithTheme sets the color theme
+func WithTheme(theme *Theme) Option {
+	return func(l *Logger) { l.Theme = theme }
+}
+
+// WithFunction shows or hides the caller
+func WithFunction(function bool) Option {
+	return func(l *Logger) { l.Function = function }
+}
+
+// WithUTC writes times in UTC or local time
+func WithUTC(utc bool) Option {
+	return func(l *Logger) { l.UTC = utc }
+}
+
+// WithFormat sets the output format
+func WithFormat(format Format) Option {
+	return func(l *Logger) { l.Format = format }
+}
+
+// WithSanitize sets the sanitize mode
+func WithSanitize(mode SanitizeMode) Option {
+	return func(l *Logger) { l.Sanitize = mode }
+}
+
+// WithStack attaches stack traces to entries at or above lvl
+func WithStack(lvl int) Option {
+	return func(l *Logger) {
+		l.Stack = true
+		l.StackLevel = lvl
+	}
+}
+
+// WithOutput sets the output writer
+func WithOutput(w io.Writer) Option {
+	return func(l *Logger) { l.out = w }
+}
+
+// WithFields adds fields to every entry
+func WithFields(fields ...Field) Option {
+	return func(l *Logger) { l.fields = append(l.fields, fields...) }
+}
+
+// WithSink adds a sink
+func WithSink(s Sink) Option {
+	return func(l *Logger) { l.sinks = append(l.sinks, s) }
+}
+
+// FromEnv reads the settings from environment variables named prefix
+// followed by _LOG_LEVEL, _LOG_DATE and so on, or LOG_LEVEL, LOG_DATE and so
+// on when prefix is empty. Variables that are not set leave the setting
+// unchanged. Invalid values leave it unchanged too, they are written as
+// warnings when the logger is created and returned by Validate.
+func FromEnv(prefix string) Option {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	return func(l *Logger) {
+		env := func(key string) (string, string, bool) {
+			name := prefix + "LOG_" + key
+			v, ok := os.LookupEnv(name)
+			return name, v, ok && v != ""
+		}
+		invalid := func(name, value, reason string) {
+			l.problems = append(l.problems, &EnvError{Var: name, Value: value, Reason: reason})
+		}
+		levelVar := func(key string, set func(int)) {
+			if name, v, ok := env(key); ok {
+				if lvl, ok := parseLevel(v); ok {
+					set(lvl)
+				} else {
+					invalid(name, v, "expected one of debug, trace, info, warn, error, fatal, panic or 1 to 6")
+				}
+			}
+		}
+		boolVar := func(key string, set func(bool)) {
+			if name, v, ok := env(key); ok {
+				if b, err := parseBool(v); err == nil {
+					set(b)
+				} else {
+					invalid(name, v, "expected true, false, 1, 0, yes, no, on or off")
+				}
+			}
+		}
+
+		levelVar("LEVEL", func(lvl int) { l.Level = lvl })
+		boolVar("DATE", func(b bool) { l.Date = b })
+		boolVar("FUNC", func(b bool) { l.Function = b })
+		boolVar("UTC", func(b bool) { l.UTC = b })
+		boolVar("STACK", func(b bool) { l.Stack = b })
+		levelVar("STACK_LEVEL", func(lvl int) { l.StackLevel = lvl })
+
+		if name, v, ok := env("COLOR"); ok {
+			if mode, ok := parseColorMode(v); ok {
+				l.ColorMode = mode
+			} else {
+				invalid(name, v, "expected auto, always, never or a boolean")
+			}
+		}
+		if name, v, ok := env("THEME"); ok {
+			if theme, ok := Themes[strings.ToLower(v)]; ok {
+				l.Theme = theme
+			} else {
+				invalid(name, v, "expected default, dark or light")
+			}
+		}
+		if name, v, ok := env("FORMAT"); ok {
+			if format, ok := formats[strings.ToLower(v)]; ok {
+				l.Format = format
+			} else {
+				invalid(name, v, "expected text, json or gelf")
+			}
+		}
+		if name, v, ok := env("SANITIZE"); ok {
+			if mode, ok := sanitizeModes[strings.ToLower(v)]; ok {
+				l.Sanitize = mode
+			} else {
+				invalid(name, v, "expected auto, none, strip or escape")
+			}
+		}
+	}
+}
+
+// EnvError describes an environment variable with an invalid value
+type EnvError struct {
+	Var    string
+	Value  string
+	Reason string
+}
+
+func (e *EnvError) Error() string {
+	return fmt.Sprintf("log: invalid %s=%q, %s", e.Var, e.Value, e.Reason)
+}
+
+// Validate returns the problems found while configuring the logger from the
+// environment, or nil
+func (l *Logger) Validate() error {
+	l = l.live()
+	return errors.Join(l.problems...)
+}
+
+// warnProblems writes a warning for every configuration problem
+func (l *Logger) warnProblems() {
+	if l.Level < level["WARN"] {
+		return
+	}
+	for _, err := range l.problems {
+		e, ok := err.(*EnvError)
+		if !ok {
+			continue
+		}
+		l.log(l.summary(summary{
+			level:  "WARN",
+			msg:    "invalid environment variable, using the default",
+			fields: []Field{F("var", e.Var), F("value", e.Value), F("reason", e.Reason)},
+		}))
+	}
+}
+
+// parseBool parses the values accepted by strconv.ParseBool and yes, no, on
+// and off
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(strings.ToLower(s))
+}
+
+// parseLevel parses a level name or number. PANIC is 0, it only logs
+// panics.
+func parseLevel(s string) (int, bool) {
+	if lvl, ok := level[strings.ToUpper(s)]; ok {
+		return lvl, true
+	}
+	if strings.ToUpper(s) == "PANIC" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil && n >= level["FATAL"] && n <= level["DEBUG"]
+}
@@ -0,0 +1,153 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("LOG_DATE", "false")
+
+	var b bytes.Buffer
+	log := logger.New(
+		logger.WithName("api"),
+		logger.WithLevel(6),
+		logger.WithFunction(false),
+		logger.WithFormat(logger.JSONFormat),
+		logger.WithOutput(&b),
+		logger.WithFields(logger.F("svc", "api")),
+	)
+	if log.Name != "api" || log.Level != 6 || !log.Date || log.Function || log.Color {
+		t.Errorf("Test expected options without env actual: %+v", log)
+	}
+	log.Debug("hello")
+	if !strings.Contains(b.String(), `"msg":"hello","svc":"api"}`) {
+		t.Errorf("Test expected JSON output actual: %s", b.String())
+	}
+}
+
+func TestFromEnvPrefix(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("MYAPP_LOG_LEVEL", "debug")
+	t.Setenv("MYAPP_LOG_UTC", "false")
+	t.Setenv("MYAPP_LOG_FORMAT", "json")
+	t.Setenv("MYAPP_LOG_COLOR", "true")
+
+	tests := []struct {
+		prefix string
+		level  int
+	}{
+		{"MYAPP", 6},
+		{"MYAPP_", 6},
+		{"", 2},
+		{"OTHER", 4},
+	}
+	for _, tt := range tests {
+		log := logger.New(logger.FromEnv(tt.prefix))
+		if log.Level != tt.level {
+			t.Errorf("Test %q expected: %d actual: %d", tt.prefix, tt.level, log.Level)
+		}
+	}
+
+	log := logger.New(logger.WithUTC(true), logger.WithFunction(false), logger.FromEnv("MYAPP"))
+	if log.UTC || log.Function || log.Format != logger.JSONFormat || log.ColorMode != logger.ColorAlways || !log.Color {
+		t.Errorf("Test expected env to override only set variables actual: %+v", log)
+	}
+}
+
+func TestFromEnvBools(t *testing.T) {
+	tests := []struct {
+		in  string
+		out bool
+	}{
+		{"true", true},
+		{"1", true},
+		{"yes", true},
+		{"ON", true},
+		{"t", true},
+		{"false", false},
+		{"0", false},
+		{"no", false},
+		{"Off", false},
+		{"F", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("LOG_DATE", tt.in)
+		log := logger.New(logger.WithDate(!tt.out), logger.FromEnv(""))
+		if log.Date != tt.out {
+			t.Errorf("Test %q expected: %v actual: %v", tt.in, tt.out, log.Date)
+		}
+		if err := log.Validate(); err != nil {
+			t.Errorf("Test %q expected valid actual: %v", tt.in, err)
+		}
+	}
+}
+
+func TestFromEnvColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		mode logger.ColorMode
+	}{
+		{"auto", logger.ColorAuto},
+		{"always", logger.ColorAlways},
+		{"Never", logger.ColorNever},
+		{"true", logger.ColorAlways},
+		{"0", logger.ColorNever},
+		{"off", logger.ColorNever},
+	}
+	for _, tt := range tests {
+		t.Setenv("LOG_COLOR", tt.in)
+		initial := logger.ColorAlways
+		if tt.mode == logger.ColorAlways {
+			initial = logger.ColorNever
+		}
+		log := logger.New(logger.WithColorMode(initial), logger.FromEnv(""))
+		if log.ColorMode != tt.mode {
+			t.Errorf("Test %q expected: %v actual: %v", tt.in, tt.mode, log.ColorMode)
+		}
+		if err := log.Validate(); err != nil {
+			t.Errorf("Test %q expected valid actual: %v", tt.in, err)
+		}
+	}
+}
+
+func TestFromEnvValidate(t *testing.T) {
+	t.Setenv("APP_LOG_DATE", "flase")
+	t.Setenv("APP_LOG_LEVEL", "verbose")
+	t.Setenv("APP_LOG_COLOR", "maybe")
+	t.Setenv("APP_LOG_FORMAT", "xml")
+	t.Setenv("APP_LOG_FUNC", "off")
+
+	var b bytes.Buffer
+	log := logger.New(logger.WithOutput(&b), logger.FromEnv("APP"))
+	if !log.Date || log.Level != 4 || log.ColorMode != logger.ColorAuto || log.Format != logger.TextFormat || log.Function {
+		t.Errorf("Test expected defaults for invalid values actual: %+v", log)
+	}
+
+	err := log.Validate()
+	if err == nil {
+		t.Fatal("Test expected validation error")
+	}
+	for _, v := range []string{`APP_LOG_LEVEL="verbose"`, `APP_LOG_DATE="flase"`, `APP_LOG_COLOR="maybe"`, `APP_LOG_FORMAT="xml"`} {
+		if !strings.Contains(err.Error(), v) {
+			t.Errorf("Test expected: %s actual: %v", v, err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Test expected: 4 warnings actual: %q", lines)
+	}
+	if !strings.Contains(lines[0], "WARN invalid environment variable, using the default var=APP_LOG_LEVEL value=verbose reason=\"expected one of") {
+		t.Errorf("Test expected structured warning actual: %s", lines[0])
+	}
+	var envErr *logger.EnvError
+	if !errors.As(err, &envErr) || envErr.Var != "APP_LOG_LEVEL" {
+		t.Errorf("Test expected EnvError actual: %v", err)
+	}
+}
@@ -0,0 +1,544 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OTLPProtocol selects the encoding used by the OTLP exporter
+type OTLPProtocol int
+
+// OTLP protocols
+const (
+	// OTLPProtobuf sends application/x-protobuf bodies
+	OTLPProtobuf OTLPProtocol = iota
+	// OTLPJSON sends application/json bodies
+	OTLPJSON
+)
+
+var errClosed = errors.New("log: sink is closed")
+
+// OTLPExporter is a sink that ships entries as OpenTelemetry log records
+// over OTLP/HTTP. Entries are batched and sent in the background every
+// Interval or once BatchSize entries are buffered. The service.name resource
+// attribute is the name of the logger.
+type OTLPExporter struct {
+	// URL is the logs endpoint, e.g. http://localhost:4318/v1/logs
+	URL       string
+	Protocol  OTLPProtocol
+	Headers   map[string]string
+	Resource  []Field
+	BatchSize int
+	Interval  time.Duration
+	// Retries is the number of times a failed export is retried, waiting
+	// Backoff doubled on each attempt
+	Retries int
+	Backoff time.Duration
+	// Timeout limits each export request
+	Timeout time.Duration
+	Client  *http.Client
+
+	once    sync.Once
+	send    sync.Mutex
+	mu      sync.Mutex
+	batch   []*Entry
+	closed  bool
+	err     error
+	flush   chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewOTLPExporter creates an exporter sending protobuf to url
+func NewOTLPExporter(url string) *OTLPExporter {
+	return &OTLPExporter{
+		URL:       url,
+		BatchSize: 512,
+		Interval:  5 * time.Second,
+		Retries:   3,
+		Backoff:   500 * time.Millisecond,
+		Timeout:   10 * time.Second,
+		Client:    http.DefaultClient,
+	}
+}
+
+func (x *OTLPExporter) start() {
+	x.once.Do(func() {
+		x.flush = make(chan struct{}, 1)
+		x.done = make(chan struct{})
+		x.stopped = make(chan struct{})
+		go x.run()
+	})
+}
+
+func (x *OTLPExporter) run() {
+	defer close(x.stopped)
+	interval := x.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-x.flush:
+		case <-x.done:
+			x.Flush()
+			return
+		}
+		x.Flush()
+	}
+}
+
+// Write buffers e for the next export. It returns the error of the last
+// failed background export, if any.
+func (x *OTLPExporter) Write(e *Entry) error {
+	x.start()
+	x.mu.Lock()
+	if x.closed {
+		x.mu.Unlock()
+		return errClosed
+	}
+	x.batch = append(x.batch, e)
+	full := len(x.batch) >= x.BatchSize
+	err := x.err
+	x.err = nil
+	x.mu.Unlock()
+
+	if full {
+		select {
+		case x.flush <- struct{}{}:
+		default:
+		}
+	}
+	return err
+}
+
+// Flush exports the buffered entries now
+func (x *OTLPExporter) Flush() error {
+	x.send.Lock()
+	defer x.send.Unlock()
+
+	x.mu.Lock()
+	batch := x.batch
+	x.batch = nil
+	x.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := x.export(batch)
+	if err != nil {
+		x.mu.Lock()
+		x.err = err
+		x.mu.Unlock()
+	}
+	return err
+}
+
+// Close exports the buffered entries and stops the exporter
+func (x *OTLPExporter) Close() error {
+	x.mu.Lock()
+	if x.closed {
+		x.mu.Unlock()
+		return nil
+	}
+	x.closed = true
+	x.mu.Unlock()
+
+	x.start()
+	close(x.done)
+	<-x.stopped
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.err
+}
+
+func (x *OTLPExporter) export(batch []*Entry) error {
+	var body []byte
+	contentType := "application/x-protobuf"
+	if x.Protocol == OTLPJSON {
+		body = x.encodeJSON(batch)
+		contentType = "application/json"
+	} else {
+		body = x.encodeProto(batch)
+	}
+
+	client := x.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	backoff := x.Backoff
+	for attempt := 0; ; attempt++ {
+		err := x.post(client, body, contentType)
+		if err == nil {
+			return nil
+		}
+		var status statusError
+		retry := !errors.As(err, &status) || status.retryable()
+		if !retry || attempt >= x.Retries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+type statusError int
+
+func (s statusError) Error() string {
+	return fmt.Sprintf("log: export failed with status %d", int(s))
+}
+
+func (s statusError) retryable() bool {
+	return s == http.StatusTooManyRequests || s == http.StatusBadGateway ||
+		s == http.StatusServiceUnavailable || s == http.StatusGatewayTimeout
+}
+
+func (x *OTLPExporter) post(client *http.Client, body []byte, contentType string) error {
+	ctx := context.Background()
+	if x.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, x.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range x.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError(resp.StatusCode)
+	}
+	return nil
+}
+
+// severityNumbers maps levels to OpenTelemetry severity numbers. DEBUG is
+// more verbose than TRACE in this package but OpenTelemetry ranks TRACE
+// lowest, so each level uses the first number of its OpenTelemetry range.
+var severityNumbers = map[string]int{
+	"TRACE": 1,
+	"DEBUG": 5,
+	"INFO":  9,
+	"WARN":  13,
+	"ERROR": 17,
+	"FATAL": 21,
+	"PANIC": 24,
+}
+
+// severityNumber returns the OpenTelemetry severity number of a level, 0
+// (unspecified) for unknown levels
+func severityNumber(logLevel string) int {
+	return severityNumbers[logLevel]
+}
+
+// otlpRecord is an entry converted to the OTLP log data model
+type otlpRecord struct {
+	time     uint64
+	severity int
+	level    string
+	body     string
+	attrs    []Field
+	traceID  []byte
+	spanID   []byte
+}
+
+type otlpResource struct {
+	name    string
+	records []otlpRecord
+}
+
+// resources groups the batch by logger name, keeping the order of entries
+func resources(batch []*Entry) []*otlpResource {
+	var res []*otlpResource
+	byName := map[string]*otlpResource{}
+	for _, e := range batch {
+		r, ok := byName[e.Logger]
+		if !ok {
+			r = &otlpResource{name: e.Logger}
+			byName[e.Logger] = r
+			res = append(res, r)
+		}
+		r.records = append(r.records, newOTLPRecord(e))
+	}
+	return res
+}
+
+func newOTLPRecord(e *Entry) otlpRecord {
+	r := otlpRecord{
+		time:     uint64(e.Time.UnixNano()),
+		severity: severityNumber(e.Level),
+		level:    e.Level,
+		body:     e.Message,
+	}
+	for _, f := range e.Fields {
+		switch f.Key {
+		case "trace_id":
+			r.traceID, _ = hex.DecodeString(fmt.Sprint(f.Value))
+		case "span_id":
+			r.spanID, _ = hex.DecodeString(fmt.Sprint(f.Value))
+		default:
+			r.attrs = append(r.attrs, f)
+		}
+	}
+	if e.Caller.File != "" {
+		r.attrs = append(r.attrs,
+			Field{Key: "code.filepath", Value: e.Caller.File},
+			Field{Key: "code.lineno", Value: e.Caller.Line},
+			Field{Key: "code.function", Value: e.Caller.Function},
+		)
+	}
+	if len(e.Stack) > 0 {
+		r.attrs = append(r.attrs, Field{Key: "code.stacktrace", Value: strings.TrimPrefix(formatStack(e.Stack), "\n")})
+	}
+	return r
+}
+
+func (x *OTLPExporter) resourceAttrs(name string) []Field {
+	if name == "" {
+		name = "unknown_service"
+	}
+	return append([]Field{{Key: "service.name", Value: name}}, x.Resource...)
+}
+
+// otlpValue normalizes a field value to one of the OTLP AnyValue kinds:
+// string, bool, int64 or float64
+func otlpValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, bool, int64, float64:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case error:
+		return v.Error()
+	}
+	return fmt.Sprint(v)
+}
+
+type otlpJSONValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpJSONKeyValue struct {
+	Key   string        `json:"key"`
+	Value otlpJSONValue `json:"value"`
+}
+
+type otlpJSONRecord struct {
+	TimeUnixNano         string             `json:"timeUnixNano"`
+	ObservedTimeUnixNano string             `json:"observedTimeUnixNano"`
+	SeverityNumber       int                `json:"severityNumber"`
+	SeverityText         string             `json:"severityText"`
+	Body                 otlpJSONValue      `json:"body"`
+	Attributes           []otlpJSONKeyValue `json:"attributes,omitempty"`
+	TraceID              string             `json:"traceId,omitempty"`
+	SpanID               string             `json:"spanId,omitempty"`
+}
+
+func jsonValue(v interface{}) otlpJSONValue {
+	switch v := otlpValue(v).(type) {
+	case bool:
+		return otlpJSONValue{BoolValue: &v}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpJSONValue{IntValue: &s}
+	case float64:
+		return otlpJSONValue{DoubleValue: &v}
+	case string:
+		return otlpJSONValue{StringValue: &v}
+	}
+	return otlpJSONValue{}
+}
+
+func jsonAttrs(fields []Field) []otlpJSONKeyValue {
+	var kvs []otlpJSONKeyValue
+	for _, f := range fields {
+		kvs = append(kvs, otlpJSONKeyValue{Key: f.Key, Value: jsonValue(f.Value)})
+	}
+	return kvs
+}
+
+func (x *OTLPExporter) encodeJSON(batch []*Entry) []byte {
+	type scope struct {
+		Name string `json:"name"`
+	}
+	type scopeLogs struct {
+		Scope      scope            `json:"scope"`
+		LogRecords []otlpJSONRecord `json:"logRecords"`
+	}
+	type resource struct {
+		Attributes []otlpJSONKeyValue `json:"attributes"`
+	}
+	type resourceLogs struct {
+		Resource  resource    `json:"resource"`
+		ScopeLogs []scopeLogs `json:"scopeLogs"`
+	}
+	var req struct {
+		ResourceLogs []resourceLogs `json:"resourceLogs"`
+	}
+
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+	for _, res := range resources(batch) {
+		sl := scopeLogs{Scope: scope{Name: pkg}}
+		for _, r := range res.records {
+			sl.LogRecords = append(sl.LogRecords, otlpJSONRecord{
+				TimeUnixNano:         strconv.FormatUint(r.time, 10),
+				ObservedTimeUnixNano: observed,
+				SeverityNumber:       r.severity,
+				SeverityText:         r.level,
+				Body:                 jsonValue(r.body),
+				Attributes:           jsonAttrs(r.attrs),
+				TraceID:              hex.EncodeToString(r.traceID),
+				SpanID:               hex.EncodeToString(r.spanID),
+			})
+		}
+		req.ResourceLogs = append(req.ResourceLogs, resourceLogs{
+			Resource:  resource{Attributes: jsonAttrs(x.resourceAttrs(res.name))},
+			ScopeLogs: []scopeLogs{sl},
+		})
+	}
+
+	b, _ := json.Marshal(req)
+	return b
+}
+
+// proto is a minimal protobuf encoder for the OTLP messages
+type proto []byte
+
+func (p *proto) varint(v uint64) {
+	*p = binary.AppendUvarint(*p, v)
+}
+
+func (p *proto) tag(field int, wire int) {
+	p.varint(uint64(field<<3 | wire))
+}
+
+func (p *proto) uint(field int, v uint64) {
+	p.tag(field, 0)
+	p.varint(v)
+}
+
+func (p *proto) fixed64(field int, v uint64) {
+	p.tag(field, 1)
+	*p = binary.LittleEndian.AppendUint64(*p, v)
+}
+
+func (p *proto) bytes(field int, b []byte) {
+	p.tag(field, 2)
+	p.varint(uint64(len(b)))
+	*p = append(*p, b...)
+}
+
+func (p *proto) string(field int, s string) {
+	p.bytes(field, []byte(s))
+}
+
+func protoValue(v interface{}) proto {
+	var p proto
+	switch v := otlpValue(v).(type) {
+	case string:
+		p.string(1, v)
+	case bool:
+		if v {
+			p.uint(2, 1)
+		} else {
+			p.uint(2, 0)
+		}
+	case int64:
+		p.uint(3, uint64(v))
+	case float64:
+		p.fixed64(4, math.Float64bits(v))
+	}
+	return p
+}
+
+func protoKeyValue(f Field) proto {
+	var p proto
+	p.string(1, f.Key)
+	p.bytes(2, protoValue(f.Value))
+	return p
+}
+
+func (x *OTLPExporter) encodeProto(batch []*Entry) []byte {
+	observed := uint64(time.Now().UnixNano())
+
+	var req proto
+	for _, res := range resources(batch) {
+		var resource proto
+		for _, f := range x.resourceAttrs(res.name) {
+			resource.bytes(1, protoKeyValue(f))
+		}
+
+		var scope proto
+		scope.string(1, pkg)
+
+		var scopeLogs proto
+		scopeLogs.bytes(1, scope)
+		for _, r := range res.records {
+			var rec proto
+			rec.fixed64(1, r.time)
+			rec.uint(2, uint64(r.severity))
+			rec.string(3, r.level)
+			rec.bytes(5, protoValue(r.body))
+			for _, f := range r.attrs {
+				rec.bytes(6, protoKeyValue(f))
+			}
+			if len(r.traceID) > 0 {
+				rec.bytes(9, r.traceID)
+			}
+			if len(r.spanID) > 0 {
+				rec.bytes(10, r.spanID)
+			}
+			rec.fixed64(11, observed)
+			scopeLogs.bytes(2, rec)
+		}
+
+		var resourceLogs proto
+		resourceLogs.bytes(1, resource)
+		resourceLogs.bytes(2, scopeLogs)
+		req.bytes(1, resourceLogs)
+	}
+	return req
+}
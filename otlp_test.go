@@ -0,0 +1,227 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+type otlpServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	fail     int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newOTLPServer(fail int) *otlpServer {
+	s := &otlpServer{fail: fail}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.fail > 0 {
+			s.fail--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, body)
+	}))
+	return s
+}
+
+func TestOTLPExporterJSON(t *testing.T) {
+	srv := newOTLPServer(0)
+	defer srv.Close()
+
+	x := logger.NewOTLPExporter(srv.URL + "/v1/logs")
+	x.Protocol = logger.OTLPJSON
+	x.Resource = []logger.Field{logger.F("deployment.environment", "test")}
+	log := newSinkLogger("checkout", 6, x)
+	log.Function = true
+	log.Extractors = []logger.ContextExtractor{logger.TraceExtractor(fakeSpanContext)}
+
+	ctx := context.WithValue(context.Background(), spanKey{}, fakeSpan{traceID: [16]byte{1}, spanID: [8]byte{2}})
+	log.With(logger.F("order", 42), logger.F("ok", true)).WarnCtx(ctx, "low stock")
+	log.Debug("debug")
+	log.Trace("trace")
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(srv.bodies) != 1 {
+		t.Fatalf("expected one request, actual %d", len(srv.bodies))
+	}
+	if ct := srv.requests[0].Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("unexpected content type %v", ct)
+	}
+
+	type value struct {
+		StringValue string `json:"stringValue"`
+		IntValue    string `json:"intValue"`
+		BoolValue   bool   `json:"boolValue"`
+	}
+	type kv struct {
+		Key   string `json:"key"`
+		Value value  `json:"value"`
+	}
+	var req struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []kv `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				LogRecords []struct {
+					SeverityNumber int    `json:"severityNumber"`
+					SeverityText   string `json:"severityText"`
+					Body           value  `json:"body"`
+					Attributes     []kv   `json:"attributes"`
+					TraceID        string `json:"traceId"`
+					SpanID         string `json:"spanId"`
+				} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal(srv.bodies[0], &req); err != nil {
+		t.Fatal(err)
+	}
+
+	res := req.ResourceLogs[0]
+	if res.Resource.Attributes[0].Key != "service.name" || res.Resource.Attributes[0].Value.StringValue != "checkout" {
+		t.Errorf("unexpected resource %+v", res.Resource)
+	}
+	if res.Resource.Attributes[1].Value.StringValue != "test" {
+		t.Errorf("unexpected resource %+v", res.Resource)
+	}
+
+	records := res.ScopeLogs[0].LogRecords
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, actual %d", len(records))
+	}
+	warn := records[0]
+	if warn.SeverityNumber != 13 || warn.SeverityText != "WARN" || warn.Body.StringValue != "low stock" {
+		t.Errorf("unexpected record %+v", warn)
+	}
+	if warn.TraceID != "01000000000000000000000000000000" || warn.SpanID != "0200000000000000" {
+		t.Errorf("unexpected trace ids %v %v", warn.TraceID, warn.SpanID)
+	}
+	if warn.Attributes[0].Key != "order" || warn.Attributes[0].Value.IntValue != "42" || !warn.Attributes[1].Value.BoolValue {
+		t.Errorf("unexpected attributes %+v", warn.Attributes)
+	}
+	if warn.Attributes[2].Key != "code.filepath" {
+		t.Errorf("expected caller attributes %+v", warn.Attributes)
+	}
+	if records[1].SeverityNumber != 5 || records[1].SeverityText != "DEBUG" {
+		t.Errorf("unexpected record %+v", records[1])
+	}
+	if records[2].SeverityNumber != 1 || records[2].SeverityText != "TRACE" {
+		t.Errorf("unexpected record %+v", records[2])
+	}
+}
+
+func TestOTLPExporterProtobufRetry(t *testing.T) {
+	srv := newOTLPServer(2)
+	defer srv.Close()
+
+	x := logger.NewOTLPExporter(srv.URL)
+	x.Backoff = time.Millisecond
+	log := newSinkLogger("checkout", 6, x)
+	log.Info("hello otlp")
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(srv.bodies) != 1 {
+		t.Fatalf("expected one request, actual %d", len(srv.bodies))
+	}
+	if ct := srv.requests[0].Header.Get("Content-Type"); ct != "application/x-protobuf" {
+		t.Errorf("unexpected content type %v", ct)
+	}
+	for _, s := range []string{"checkout", "service.name", "hello otlp", "INFO"} {
+		if !bytes.Contains(srv.bodies[0], []byte(s)) {
+			t.Errorf("expected %q in body", s)
+		}
+	}
+}
+
+func TestOTLPExporterError(t *testing.T) {
+	srv := newOTLPServer(10)
+	defer srv.Close()
+
+	x := logger.NewOTLPExporter(srv.URL)
+	x.Retries = 1
+	x.Backoff = time.Millisecond
+	log := newSinkLogger("checkout", 6, x)
+	log.Info("hello")
+	if err := log.Close(); err == nil {
+		t.Errorf("expected export error")
+	}
+	if err := x.Write(&logger.Entry{}); err == nil {
+		t.Errorf("expected error writing to closed exporter")
+	}
+}
+
+func TestOTLPExporterTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	x := logger.NewOTLPExporter(srv.URL)
+	x.Timeout = 50 * time.Millisecond
+	x.Retries = 0
+	x.Write(&logger.Entry{Level: "INFO", Message: "hello"})
+
+	done := make(chan error, 1)
+	go func() { done <- x.Close() }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected timeout error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on a collector that never answers")
+	}
+}
+
+func TestOTLPExporterBatchSize(t *testing.T) {
+	srv := newOTLPServer(0)
+	defer srv.Close()
+
+	x := logger.NewOTLPExporter(srv.URL)
+	x.BatchSize = 2
+	log := newSinkLogger("checkout", 6, x)
+	log.Info("one")
+	log.Info("two")
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		srv.mu.Lock()
+		n := len(srv.bodies)
+		srv.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	srv.mu.Lock()
+	if len(srv.bodies) != 1 {
+		t.Errorf("expected a full batch to be sent, actual %d requests", len(srv.bodies))
+	}
+	srv.mu.Unlock()
+	log.Close()
+}
@@ -0,0 +1,212 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate is a token bucket that allows Burst entries at once and refills at
+// Limit entries per second. The zero Rate is unlimited.
+type Rate struct {
+	Limit float64
+	Burst int
+}
+
+// RateLimiter caps the number of entries written globally, per logger name
+// and per caller file:line. An entry is written only when every bucket it
+// belongs to has a token left. Once a bucket that dropped entries allows
+// one again an entry reporting the number of dropped entries is written
+// through the logger that wrote the last dropped entry, even if no further
+// entries arrive.
+type RateLimiter struct {
+	Global    Rate
+	PerLogger Rate
+	PerCaller Rate
+
+	mu      sync.Mutex
+	buckets map[rateKey]*bucket
+	timer   *time.Timer
+}
+
+type rateKey struct {
+	scope string
+	key   string
+}
+
+type bucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int
+	logger     *Logger
+	level      string
+}
+
+// NewRateLimiter creates a rate limiter with a global limit
+func NewRateLimiter(limit float64, burst int) *RateLimiter {
+	return &RateLimiter{Global: Rate{Limit: limit, Burst: burst}}
+}
+
+// allow reports whether e, written by l, is written and returns the
+// summaries of buckets that allow entries again
+func (r *RateLimiter) allow(l *Logger, e *Entry) (bool, []summary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.buckets == nil {
+		r.buckets = map[rateKey]*bucket{}
+	}
+	now := time.Now()
+
+	var keys []rateKey
+	var rates []Rate
+	add := func(rate Rate, k rateKey) {
+		if rate != (Rate{}) {
+			keys = append(keys, k)
+			rates = append(rates, rate)
+		}
+	}
+	add(r.Global, rateKey{scope: "global"})
+	add(r.PerLogger, rateKey{scope: "logger", key: e.Logger})
+	if e.Caller.File != "" {
+		add(r.PerCaller, rateKey{scope: "caller", key: e.Caller.File + ":" + strconv.Itoa(e.Caller.Line)})
+	}
+
+	buckets := make([]*bucket, len(keys))
+	for i, k := range keys {
+		b, ok := r.buckets[k]
+		if !ok {
+			b = &bucket{tokens: float64(burst(rates[i])), last: now}
+			r.buckets[k] = b
+		}
+		b.refill(rates[i], now)
+		buckets[i] = b
+	}
+	for _, b := range buckets {
+		if b.tokens < 1 {
+			b.suppressed++
+			b.logger, b.level = l, e.Level
+			r.schedule()
+			return false, nil
+		}
+	}
+
+	var summaries []summary
+	for i, b := range buckets {
+		b.tokens--
+		if b.suppressed > 0 {
+			summaries = append(summaries, keys[i].summary(e.Level, b.suppressed))
+			b.suppressed, b.logger = 0, nil
+		}
+	}
+	return true, summaries
+}
+
+// rate returns the rate of the buckets of scope
+func (r *RateLimiter) rate(scope string) Rate {
+	switch scope {
+	case "logger":
+		return r.PerLogger
+	case "caller":
+		return r.PerCaller
+	}
+	return r.Global
+}
+
+// schedule starts the timer for the bucket with dropped entries that gets
+// a token first unless it is already running
+func (r *RateLimiter) schedule() {
+	if r.timer != nil {
+		return
+	}
+	first := true
+	var next time.Duration
+	for k, b := range r.buckets {
+		rate := r.rate(k.scope)
+		if b.suppressed == 0 || rate.Limit <= 0 {
+			continue
+		}
+		d := time.Duration((1 - b.tokens) / rate.Limit * float64(time.Second))
+		if first || d < next {
+			first, next = false, d
+		}
+	}
+	if !first {
+		r.timer = time.AfterFunc(next, r.sweep)
+	}
+}
+
+// sweep reports the dropped entries of buckets that allow entries again, so
+// that buckets of callers which stopped logging are still reported
+func (r *RateLimiter) sweep() {
+	type report struct {
+		logger  *Logger
+		summary summary
+	}
+	var reports []report
+
+	r.mu.Lock()
+	r.timer = nil
+	now := time.Now()
+	for k, b := range r.buckets {
+		if b.suppressed == 0 {
+			continue
+		}
+		if b.refill(r.rate(k.scope), now); b.tokens >= 1 {
+			reports = append(reports, report{b.logger, k.summary(b.level, b.suppressed)})
+			b.suppressed, b.logger = 0, nil
+		}
+	}
+	r.schedule()
+	r.mu.Unlock()
+
+	for _, rep := range reports {
+		rep.logger.emit(rep.logger.summary(rep.summary))
+	}
+}
+
+// flush returns the summaries of all buckets that dropped entries
+func (r *RateLimiter) flush() []summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	var summaries []summary
+	for k, b := range r.buckets {
+		if b.suppressed > 0 {
+			summaries = append(summaries, k.summary("WARN", b.suppressed))
+			b.suppressed, b.logger = 0, nil
+		}
+	}
+	return summaries
+}
+
+func (b *bucket) refill(rate Rate, now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * rate.Limit
+	if size := float64(burst(rate)); b.tokens > size {
+		b.tokens = size
+	}
+	b.last = now
+}
+
+// burst returns the bucket size of rate, at least one entry
+func burst(rate Rate) int {
+	if rate.Burst < 1 {
+		return 1
+	}
+	return rate.Burst
+}
+
+func (k rateKey) summary(level string, n int) summary {
+	s := summary{
+		level:  level,
+		msg:    fmt.Sprintf("rate limit suppressed %d entries", n),
+		fields: []Field{F("limit", k.scope)},
+	}
+	if k.scope != "global" {
+		s.fields = append(s.fields, F(k.scope, k.key))
+	}
+	return s
+}
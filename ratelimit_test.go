@@ -0,0 +1,117 @@
+package log_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestRateLimitGlobal(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4, RateLimiter: logger.NewRateLimiter(20, 3)}
+	log.SetOutput(&b)
+
+	written := 0
+	for i := 0; i < 10; i++ {
+		if log.Errorf("failed %d", i) != "" {
+			written++
+		}
+	}
+	if written != 3 {
+		t.Errorf("Test expected burst of: 3 actual: %d", written)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if log.Error("recovered") == "" {
+		t.Fatalf("Test expected entry after refill")
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 5 || !strings.Contains(lines[3], "ERROR rate limit suppressed 7 entries limit=global") {
+		t.Errorf("Test expected summary before entry actual: %q", lines)
+	}
+}
+
+func TestRateLimitWithoutNewEntries(t *testing.T) {
+	var b syncBuffer
+	log := &logger.Logger{Level: 4, RateLimiter: logger.NewRateLimiter(20, 1)}
+	log.SetOutput(&b)
+
+	for i := 0; i < 5; i++ {
+		log.Error("failed")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if !strings.Contains(b.String(), "ERROR rate limit suppressed 4 entries limit=global") {
+		t.Errorf("Test expected summary without new entries actual: %s", b.String())
+	}
+	log.Close()
+	if n := strings.Count(b.String(), "rate limit suppressed"); n != 1 {
+		t.Errorf("Test expected one summary actual: %d", n)
+	}
+}
+
+func TestRateLimitPerLoggerAndCaller(t *testing.T) {
+	var b bytes.Buffer
+	limiter := &logger.RateLimiter{
+		PerLogger: logger.Rate{Limit: 0.001, Burst: 4},
+		PerCaller: logger.Rate{Limit: 0.001, Burst: 1},
+	}
+	db := &logger.Logger{Name: "db", Level: 4, RateLimiter: limiter}
+	db.SetOutput(&b)
+	api := &logger.Logger{Name: "api", Level: 4, RateLimiter: limiter}
+	api.SetOutput(&b)
+
+	written := 0
+	for i := 0; i < 3; i++ {
+		if db.Warn("loop a") != "" {
+			written++
+		}
+		if db.Warn("loop b") != "" {
+			written++
+		}
+	}
+	if written != 2 {
+		t.Errorf("Test expected one entry per call site actual: %d", written)
+	}
+	if api.Warn("other logger") == "" {
+		t.Errorf("Test expected other logger not limited")
+	}
+
+	db.Close()
+	out := b.String()
+	if strings.Count(out, "rate limit suppressed 2 entries limit=caller caller=") != 2 {
+		t.Errorf("Test expected caller summaries on close actual: %s", out)
+	}
+	if strings.Contains(out, "caller=[") || strings.Contains(out, "[ratelimit_test.go") {
+		t.Errorf("Test expected caller hidden when Function is off actual: %s", out)
+	}
+}
+
+func TestRateLimitFatal(t *testing.T) {
+	if os.Getenv("LOG_TEST_FATAL") == "1" {
+		log := &logger.Logger{
+			Level:       4,
+			RateLimiter: logger.NewRateLimiter(0.001, 1),
+			Sampler:     logger.NewSampler(map[string]logger.Sampling{"FATAL": {}}),
+			Dedup:       logger.NewDedup(time.Hour),
+		}
+		log.SetOutput(os.Stdout)
+		log.Error("failed")
+		log.Fatal("giving up")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRateLimitFatal$")
+	cmd.Env = append(os.Environ(), "LOG_TEST_FATAL=1")
+	out, err := cmd.Output()
+	if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 1 {
+		t.Fatalf("Test expected exit status 1 actual: %v", err)
+	}
+	if !strings.Contains(string(out), "FATAL giving up") {
+		t.Errorf("Test expected FATAL entry actual: %q", out)
+	}
+}
@@ -0,0 +1,277 @@
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Strategy returns the replacement for a sensitive value
+type Strategy func(s string) string
+
+// Mask replaces the value with [REDACTED]
+func Mask(s string) string {
+	return "[REDACTED]"
+}
+
+// Hash replaces the value with the start of its SHA-256 hash so equal values
+// can still be correlated
+func Hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// Partial returns a strategy that masks all but the last n characters
+func Partial(n int) Strategy {
+	return func(s string) string {
+		r := []rune(s)
+		if len(r) <= n {
+			return strings.Repeat("*", len(r))
+		}
+		return strings.Repeat("*", len(r)-n) + string(r[len(r)-n:])
+	}
+}
+
+// Rule replaces matches of Pattern in messages and field values.
+// When Pattern has a group only the first group is replaced. When Valid is
+// set, matches it rejects are left as is.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Replace Strategy
+	Valid   func(s string) bool
+}
+
+// Built-in rules
+var (
+	CreditCardRule = Rule{
+		Name:    "credit_card",
+		Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Replace: Partial(4),
+		Valid:   luhn,
+	}
+	BearerRule = Rule{
+		Name:    "bearer",
+		Pattern: regexp.MustCompile(`(?i)\bbearer\s+([a-z0-9\-._~+/]+=*)`),
+		Replace: Mask,
+	}
+	EmailRule = Rule{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+		Replace: Mask,
+	}
+)
+
+// DenyFields are the field names redacted by NewRedactor
+var DenyFields = []string{"password", "passwd", "secret", "authorization", "token", "api_key", "apikey"}
+
+// Redactor removes sensitive data from entries before they are encoded.
+// Fields whose lower cased name contains one of Fields are replaced with
+// Replace, Rules are applied to the message and field values. Error values
+// keep their chain and are redacted when they are encoded, other values are
+// replaced with their redacted fmt.Sprint form when a rule matches.
+type Redactor struct {
+	Fields  []string
+	Rules   []Rule
+	Replace Strategy
+}
+
+// NewRedactor creates a redactor with DenyFields and the built-in rules
+func NewRedactor() *Redactor {
+	return &Redactor{
+		Fields:  DenyFields,
+		Rules:   []Rule{CreditCardRule, BearerRule, EmailRule},
+		Replace: Mask,
+	}
+}
+
+// redact applies the logger's redactor to e
+func (l *Logger) redact(e *Entry) *Entry {
+	if l.Redactor == nil {
+		return e
+	}
+	return l.Redactor.redact(e)
+}
+
+// redact returns a copy of e with sensitive data replaced
+func (r *Redactor) redact(e *Entry) *Entry {
+	c := *e
+	c.Message = r.apply(e.Message)
+	c.Fields = make([]Field, len(e.Fields))
+	for i, f := range e.Fields {
+		c.Fields[i] = r.field(f)
+	}
+	return &c
+}
+
+// redactHooked redacts the message and fields the hooks changed or added
+// in e, before is the redacted entry the hooks ran on. Values that are
+// already redacted are kept so that hashes are not hashed again.
+func (r *Redactor) redactHooked(before, e *Entry) *Entry {
+	done := map[[2]string]bool{}
+	for _, f := range before.Fields {
+		if v, ok := f.Value.(string); ok {
+			done[[2]string{f.Key, v}] = true
+		}
+	}
+	c := *e
+	if c.Message != before.Message {
+		c.Message = r.apply(c.Message)
+	}
+	c.Fields = make([]Field, len(e.Fields))
+	for i, f := range e.Fields {
+		if v, ok := f.Value.(string); ok && done[[2]string{f.Key, v}] {
+			c.Fields[i] = f
+			continue
+		}
+		c.Fields[i] = r.field(f)
+	}
+	return &c
+}
+
+func (r *Redactor) field(f Field) Field {
+	switch v := f.Value.(type) {
+	case nil, Redacted, redactedError:
+	case string:
+		f.Value = r.apply(v)
+	case error:
+		msg, verbose := v.Error(), fmt.Sprintf("%+v", v)
+		if r.apply(msg) != msg || r.apply(verbose) != verbose {
+			f.Value = redactedError{err: v, redactor: r}
+		}
+	default:
+		if s := fmt.Sprint(v); r.apply(s) != s {
+			f.Value = r.apply(s)
+		}
+	}
+	if r.denied(f.Key) {
+		replace := r.Replace
+		if replace == nil {
+			replace = Mask
+		}
+		f.Value = replace(fmt.Sprint(Reveal(f.Value)))
+	}
+	return f
+}
+
+func (r *Redactor) denied(key string) bool {
+	key = strings.ToLower(key)
+	for _, name := range r.Fields {
+		if strings.Contains(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) apply(s string) string {
+	for _, rule := range r.Rules {
+		s = rule.apply(s)
+	}
+	return s
+}
+
+func (r Rule) apply(s string) string {
+	return r.Pattern.ReplaceAllStringFunc(s, func(m string) string {
+		if r.Valid != nil && !r.Valid(m) {
+			return m
+		}
+		if r.Pattern.NumSubexp() > 0 {
+			if sub := r.Pattern.FindStringSubmatchIndex(m); sub != nil && sub[2] >= 0 {
+				return m[:sub[2]] + r.Replace(m[sub[2]:sub[3]]) + m[sub[3]:]
+			}
+		}
+		return r.Replace(m)
+	})
+}
+
+// luhn reports whether the digits of s have a valid Luhn check digit, so
+// that long numbers such as timestamps are not taken for card numbers
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		d := int(s[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactedError is an error field value whose messages match a rule. The
+// rules are applied when it is printed or encoded, Unwrap keeps errors.Is
+// and errors.As working.
+type redactedError struct {
+	err      error
+	redactor *Redactor
+}
+
+func (e redactedError) Error() string {
+	return e.redactor.apply(e.err.Error())
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
+
+// Format redacts the verbose form of the error as well
+func (e redactedError) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('+') {
+		f.Write([]byte(e.redactor.apply(fmt.Sprintf("%+v", e.err))))
+		return
+	}
+	f.Write([]byte(e.Error()))
+}
+
+// Redacted wraps a sensitive value. It is written as [REDACTED] whatever
+// the format, verb or encoder.
+type Redacted struct {
+	value interface{}
+}
+
+// Redact wraps v
+func Redact(v interface{}) Redacted {
+	return Redacted{value: v}
+}
+
+// Reveal returns the value wrapped by Redact, other values are returned as
+// is
+func Reveal(v interface{}) interface{} {
+	if r, ok := v.(Redacted); ok {
+		return r.value
+	}
+	return v
+}
+
+func (Redacted) String() string {
+	return "[REDACTED]"
+}
+
+// GoString keeps %#v from printing the value
+func (Redacted) GoString() string {
+	return "[REDACTED]"
+}
+
+// Format keeps every fmt verb from printing the value
+func (Redacted) Format(f fmt.State, verb rune) {
+	f.Write([]byte("[REDACTED]"))
+}
+
+// MarshalJSON keeps JSON encoders from printing the value
+func (Redacted) MarshalJSON() ([]byte, error) {
+	return []byte(`"[REDACTED]"`), nil
+}
+
+// MarshalText keeps text encoders from printing the value
+func (Redacted) MarshalText() ([]byte, error) {
+	return []byte("[REDACTED]"), nil
+}
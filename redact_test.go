@@ -0,0 +1,185 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestRedactMessage(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4, Redactor: logger.NewRedactor()}
+	log.SetOutput(&b)
+
+	tests := []struct {
+		msg      string
+		expected string
+	}{
+		{"card 4111 1111 1111 1111 declined", "card ***************1111 declined"},
+		{"card 4111-1111-1111-1111", "card ***************1111"},
+		{"started 1760884800123456789", "started 1760884800123456789"},
+		{"not a card 4111 1111 1111 1234", "not a card 4111 1111 1111 1234"},
+		{"header Authorization: Bearer abc.def-123", "header Authorization: Bearer [REDACTED]"},
+		{"sent to jane.doe@example.com", "sent to [REDACTED]"},
+		{"order 1234 shipped", "order 1234 shipped"},
+	}
+	for _, tt := range tests {
+		b.Reset()
+		log.Infof("%s", tt.msg)
+		if actual := b.String()[18 : b.Len()-1]; actual != tt.expected {
+			t.Errorf("Test %q expected: %q actual: %q", tt.msg, tt.expected, actual)
+		}
+	}
+}
+
+func TestRedactFields(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4, Format: logger.JSONFormat, Redactor: logger.NewRedactor()}
+	log.Redactor.Replace = logger.Hash
+	log.SetOutput(&b)
+
+	log.With(
+		logger.F("db_password", "hunter2"),
+		logger.F("Authorization", logger.Redact("Basic xyz")),
+		logger.F("user", "bob@example.com"),
+		logger.F("count", 3),
+	).Info("login")
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"db_password":   logger.Hash("hunter2"),
+		"Authorization": logger.Hash("Basic xyz"),
+		"user":          "[REDACTED]",
+		"count":         float64(3),
+	}
+	for k, v := range expected {
+		if m[k] != v {
+			t.Errorf("Test %s expected: %v actual: %v", k, v, m[k])
+		}
+	}
+	if strings.Contains(b.String(), "hunter2") {
+		t.Errorf("Test expected password hidden actual: %s", b.String())
+	}
+}
+
+func TestRedactErrors(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4, Redactor: logger.NewRedactor()}
+	log.SetOutput(&b)
+
+	cause := errors.New("card 4111 1111 1111 1111")
+	log.ErrorE(fmt.Errorf("auth failed: Bearer abc.def.ghi for bob@example.com"), "login")
+	log.With(logger.F("upstream", fmt.Errorf("charge: %w", cause))).Warn("retrying")
+	log.With(logger.F("trace", verboseError{})).Warn("verbose")
+	log.With(logger.F("addr", &mail{"bob@example.com"})).Info("sent")
+	out := b.String()
+	for _, s := range []string{"abc.def.ghi", "bob@example.com", "4111 1111 1111 1111", "jane@example.com"} {
+		if strings.Contains(out, s) {
+			t.Errorf("Test expected %q redacted actual: %s", s, out)
+		}
+	}
+	for _, s := range []string{
+		`error="auth failed: Bearer [REDACTED] for [REDACTED]"`,
+		`upstream="charge: card ***************1111" upstream_types=*fmt.wrapError,*errors.errorString`,
+		`addr=<[REDACTED]>`,
+		"\n\tconnect\n\t\tmail to [REDACTED]",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Test expected %q actual: %s", s, out)
+		}
+	}
+
+	b.Reset()
+	log.Format = logger.JSONFormat
+	log.With(logger.F("upstream", fmt.Errorf("charge: %w", cause))).Warn("retrying")
+	var m struct {
+		Upstream struct {
+			Msg   string `json:"msg"`
+			Chain []struct {
+				Msg string `json:"msg"`
+			} `json:"chain"`
+		} `json:"upstream"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Upstream.Msg != "charge: card ***************1111" || len(m.Upstream.Chain) != 1 || m.Upstream.Chain[0].Msg != "card ***************1111" {
+		t.Errorf("Test expected redacted chain actual: %s", b.String())
+	}
+
+	var hooked error
+	log.AddHook(logger.HookFunc(func(e *logger.Entry) bool {
+		hooked, _ = e.Fields[0].Value.(error)
+		return true
+	}))
+	log.ErrorE(cause, "failed")
+	if !errors.Is(hooked, cause) {
+		t.Errorf("Test expected redacted error to unwrap to the cause")
+	}
+}
+
+// verboseError only prints the address with %+v
+type verboseError struct{}
+
+func (verboseError) Error() string { return "connect" }
+
+func (e verboseError) Format(s fmt.State, verb rune) {
+	io.WriteString(s, "connect")
+	if verb == 'v' && s.Flag('+') {
+		io.WriteString(s, "\n\tmail to jane@example.com")
+	}
+}
+
+type mail struct {
+	to string
+}
+
+func (m *mail) String() string {
+	return "<" + m.to + ">"
+}
+
+func TestPartial(t *testing.T) {
+	if s := logger.Partial(2)("secret"); s != "****et" {
+		t.Errorf("Test expected: ****et actual: %s", s)
+	}
+	if s := logger.Partial(4)("abc"); s != "***" {
+		t.Errorf("Test expected: *** actual: %s", s)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	r := logger.Redact("hunter2")
+	for _, s := range []string{
+		r.String(),
+		fmt.Sprint(r),
+		fmt.Sprintf("%v %+v %#v %s %q %x", r, r, r, r, r, r),
+	} {
+		if strings.Contains(s, "hunter2") || strings.Contains(s, "68756e74657232") {
+			t.Errorf("Test expected value hidden actual: %s", s)
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"p": r})
+	if string(data) != `{"p":"[REDACTED]"}` {
+		t.Errorf("Test expected redacted JSON actual: %s", data)
+	}
+	if logger.Reveal(r) != "hunter2" {
+		t.Errorf("Test expected Reveal to return the value")
+	}
+
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4}
+	log.SetOutput(&b)
+	log.With(logger.F("key", r)).Info("no redactor")
+	if !strings.HasSuffix(b.String(), "no redactor key=[REDACTED]\n") {
+		t.Errorf("Test expected redacted field actual: %s", b.String())
+	}
+}
@@ -0,0 +1,184 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sampling keeps the first First entries with the same level and message
+// template in every Interval and then every Thereafter-th one. A Thereafter
+// of 0 drops the rest and an Interval of 0 is one second.
+type Sampling struct {
+	First      int
+	Thereafter int
+	Interval   time.Duration
+}
+
+func (c Sampling) interval() time.Duration {
+	if c.Interval <= 0 {
+		return time.Second
+	}
+	return c.Interval
+}
+
+// Sampler drops repeated entries of the levels in Levels. Entries are
+// grouped by level and message template so Infof calls with different
+// arguments count together. When an interval ends an entry reporting the
+// number of suppressed entries is written through the logger that wrote the
+// first entry of the group, even if no further entries arrive.
+type Sampler struct {
+	Levels map[string]Sampling
+
+	mu     sync.Mutex
+	counts map[sampleKey]*sampleCount
+	timer  *time.Timer
+}
+
+type sampleKey struct {
+	level    string
+	template string
+}
+
+type sampleCount struct {
+	logger     *Logger
+	start      time.Time
+	n          int
+	suppressed int
+}
+
+// summary describes entries dropped by a sampler or rate limiter
+type summary struct {
+	level  string
+	msg    string
+	fields []Field
+}
+
+// NewSampler creates a sampler for levels
+func NewSampler(levels map[string]Sampling) *Sampler {
+	return &Sampler{Levels: levels}
+}
+
+// sample reports whether e, written by l, is kept and returns the suppressed
+// count of its group when the interval of the group ended
+func (s *Sampler) sample(l *Logger, e *Entry) (bool, []summary) {
+	cfg, ok := s.Levels[e.Level]
+	if !ok {
+		return true, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts == nil {
+		s.counts = map[sampleKey]*sampleCount{}
+	}
+	now := time.Now()
+	var summaries []summary
+
+	key := sampleKey{level: e.Level, template: e.key()}
+	c, ok := s.counts[key]
+	if ok && now.Sub(c.start) >= cfg.interval() {
+		if c.suppressed > 0 {
+			summaries = append(summaries, key.summary(c.suppressed))
+		}
+		ok = false
+	}
+	if !ok {
+		c = &sampleCount{logger: l, start: now}
+		s.counts[key] = c
+		s.schedule(now)
+	}
+
+	c.n++
+	if c.n <= cfg.First || (cfg.Thereafter > 0 && (c.n-cfg.First)%cfg.Thereafter == 0) {
+		return true, summaries
+	}
+	c.suppressed++
+	return false, summaries
+}
+
+// schedule starts the timer for the group whose interval ends first unless
+// it is already running
+func (s *Sampler) schedule(now time.Time) {
+	if s.timer != nil || len(s.counts) == 0 {
+		return
+	}
+	first := true
+	var next time.Duration
+	for key, c := range s.counts {
+		if d := s.Levels[key.level].interval() - now.Sub(c.start); first || d < next {
+			first, next = false, d
+		}
+	}
+	s.timer = time.AfterFunc(next, s.sweep)
+}
+
+// sweep removes the groups whose interval ended and reports their
+// suppressed counts, so that groups which stopped logging are still reported
+func (s *Sampler) sweep() {
+	type report struct {
+		logger  *Logger
+		summary summary
+	}
+	var reports []report
+
+	s.mu.Lock()
+	s.timer = nil
+	now := time.Now()
+	for key, c := range s.counts {
+		if now.Sub(c.start) < s.Levels[key.level].interval() {
+			continue
+		}
+		if c.suppressed > 0 {
+			reports = append(reports, report{c.logger, key.summary(c.suppressed)})
+		}
+		delete(s.counts, key)
+	}
+	s.schedule(now)
+	s.mu.Unlock()
+
+	for _, r := range reports {
+		r.logger.emit(r.logger.summary(r.summary))
+	}
+}
+
+func (k sampleKey) summary(n int) summary {
+	return summary{
+		level:  k.level,
+		msg:    fmt.Sprintf("sampling suppressed %d entries", n),
+		fields: []Field{F("template", k.template)},
+	}
+}
+
+// flush returns the suppressed counts of all groups and resets them
+func (s *Sampler) flush() []summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	var summaries []summary
+	for key, c := range s.counts {
+		if c.suppressed > 0 {
+			summaries = append(summaries, key.summary(c.suppressed))
+		}
+	}
+	s.counts = nil
+	return summaries
+}
+
+// summary creates the entry reporting suppressed entries
+func (l *Logger) summary(s summary) *Entry {
+	e := &Entry{
+		Logger:  l.Name,
+		Time:    time.Now(),
+		Level:   s.level,
+		Message: s.msg,
+		Fields:  append(l.fields[:len(l.fields):len(l.fields)], s.fields...),
+	}
+	if l.UTC {
+		e.Time = e.Time.UTC()
+	}
+	return e
+}
@@ -0,0 +1,125 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestSampling(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 6}
+	log.Sampler = logger.NewSampler(map[string]logger.Sampling{
+		"DEBUG": {First: 2, Thereafter: 3, Interval: time.Hour},
+	})
+	log.SetOutput(&b)
+
+	var kept []int
+	for i := 1; i <= 10; i++ {
+		if log.With(logger.F("i", i)).Debugf("request %d done", i) != "" {
+			kept = append(kept, i)
+		}
+	}
+	log.Info("not sampled")
+	log.Info("not sampled")
+
+	expected := []int{1, 2, 5, 8}
+	if len(kept) != len(expected) {
+		t.Fatalf("Test expected: %v actual: %v", expected, kept)
+	}
+	for i := range expected {
+		if kept[i] != expected[i] {
+			t.Errorf("Test expected: %v actual: %v", expected, kept)
+		}
+	}
+	if n := strings.Count(b.String(), "not sampled"); n != 2 {
+		t.Errorf("Test expected: 2 INFO lines actual: %d", n)
+	}
+
+	log.Close()
+	if !strings.Contains(b.String(), `DEBUG sampling suppressed 6 entries template="request %d done"`) {
+		t.Errorf("Test expected summary actual: %s", b.String())
+	}
+}
+
+func TestSamplingDefaultInterval(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4}
+	log.Sampler = logger.NewSampler(map[string]logger.Sampling{
+		"INFO": {First: 1},
+	})
+	log.SetOutput(&b)
+
+	for i := 0; i < 10; i++ {
+		log.Info("polling")
+	}
+	log.Close()
+	if n := strings.Count(b.String(), "polling"); n != 2 || !strings.Contains(b.String(), "sampling suppressed 9 entries") {
+		t.Errorf("Test expected one entry and a summary actual: %s", b.String())
+	}
+}
+
+func TestSamplingInterval(t *testing.T) {
+	var b bytes.Buffer
+	log := &logger.Logger{Level: 4}
+	log.Sampler = logger.NewSampler(map[string]logger.Sampling{
+		"WARN": {First: 1, Interval: 50 * time.Millisecond},
+	})
+	log.SetOutput(&b)
+
+	log.Warn("retrying")
+	log.Warn("retrying")
+	log.Warn("retrying")
+	time.Sleep(60 * time.Millisecond)
+	if log.Warn("retrying") == "" {
+		t.Errorf("Test expected entry after interval")
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "sampling suppressed 2 entries") || !strings.HasSuffix(lines[2], "retrying") {
+		t.Errorf("Test expected summary before next entry actual: %q", lines)
+	}
+}
+
+func TestSamplingIntervalWithoutNewEntries(t *testing.T) {
+	var b syncBuffer
+	log := &logger.Logger{Level: 4}
+	log.Sampler = logger.NewSampler(map[string]logger.Sampling{
+		"WARN": {First: 1, Interval: 50 * time.Millisecond},
+	})
+	log.SetOutput(&b)
+
+	log.Warn("retrying")
+	log.Warn("retrying")
+	log.Warn("retrying")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(b.String(), "sampling suppressed 2 entries") {
+		if time.Now().After(deadline) {
+			t.Fatalf("Test expected summary when the interval ends actual: %q", b.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for entries written by timers
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
@@ -0,0 +1,95 @@
+package log
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SanitizeMode controls how control characters and ANSI escape sequences in
+// messages and field values are handled
+type SanitizeMode int
+
+// Sanitize modes
+const (
+	// SanitizeAuto uses SanitizeStrip for machine formats and SanitizeNone
+	// for text
+	SanitizeAuto SanitizeMode = iota
+	// SanitizeNone writes messages verbatim
+	SanitizeNone
+	// SanitizeStrip escapes CR and LF and removes ANSI sequences and other
+	// control characters
+	SanitizeStrip
+	// SanitizeEscape escapes all control characters, including the ESC of
+	// ANSI sequences, so they are visible in the output
+	SanitizeEscape
+)
+
+var sanitizeModes = map[string]SanitizeMode{
+	"auto":   SanitizeAuto,
+	"none":   SanitizeNone,
+	"strip":  SanitizeStrip,
+	"escape": SanitizeEscape,
+}
+
+// ansi matches CSI, OSC and two byte escape sequences
+var ansi = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+
+func (l *Logger) sanitizeMode() SanitizeMode {
+	if l.Sanitize != SanitizeAuto {
+		return l.Sanitize
+	}
+	if l.Format == TextFormat {
+		return SanitizeNone
+	}
+	return SanitizeStrip
+}
+
+func sanitize(s string, mode SanitizeMode) string {
+	switch mode {
+	case SanitizeStrip:
+		s = ansi.ReplaceAllString(s, "")
+	case SanitizeEscape:
+	default:
+		return s
+	}
+
+	clean := true
+	for _, r := range s {
+		if r != '\t' && unicode.IsControl(r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t' || !unicode.IsControl(r):
+			b.WriteRune(r)
+		case mode == SanitizeEscape && r < 0x100:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case mode == SanitizeEscape:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+	}
+	return b.String()
+}
+
+// sanitizeLines sanitizes every line of s, keeping the line breaks of
+// multi-line text such as stack traces
+func sanitizeLines(s string, mode SanitizeMode) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = sanitize(line, mode)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,83 @@
+package log_test
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestSanitizeText(t *testing.T) {
+	msg := "a\nb\r\tc \033[31mred\033[0m \x07bell"
+	var tests = []struct {
+		mode  logger.SanitizeMode
+		regex string
+	}{
+		{logger.SanitizeAuto, `^\S+ INFO a\nb\r\tc \x1b\[31mred\x1b\[0m \x07bell$`},
+		{logger.SanitizeNone, `^\S+ INFO a\nb\r\tc \x1b\[31mred\x1b\[0m \x07bell$`},
+		{logger.SanitizeStrip, `^\S+ INFO a\\nb\\r\tc red bell$`},
+		{logger.SanitizeEscape, `^\S+ INFO a\\nb\\r\tc \\x1b\[31mred\\x1b\[0m \\x07bell$`},
+	}
+
+	for i, tt := range tests {
+		log := &logger.Logger{Level: 4, Sanitize: tt.mode}
+		log.SetOutput(&bytes.Buffer{})
+		s := log.Info(msg)
+		if !regexp.MustCompile(tt.regex).MatchString(s) {
+			t.Errorf("Test(%d) expected: %v actual: %q", i, tt.regex, s)
+		}
+	}
+}
+
+func TestSanitizeFields(t *testing.T) {
+	log := &logger.Logger{Level: 4, Sanitize: logger.SanitizeStrip}
+	log.SetOutput(&bytes.Buffer{})
+	s := log.With(logger.F("user", "bob\nINFO forged")).Info("info")
+	re := regexp.MustCompile(`^\S+ INFO info user="bob\\nINFO forged"$`)
+	if !re.MatchString(s) {
+		t.Errorf("Test expected: %v actual: %q", re, s)
+	}
+}
+
+func TestSanitizeJSON(t *testing.T) {
+	var tests = []struct {
+		mode logger.SanitizeMode
+		out  string
+	}{
+		{logger.SanitizeAuto, `"msg":"a\\nb red","user":"x\\ny"}`},
+		{logger.SanitizeNone, `"msg":"a\nb \u001b[31mred\u001b[0m","user":"x\ny"}`},
+		{logger.SanitizeEscape, `"msg":"a\\nb \\x1b[31mred\\x1b[0m","user":"x\\ny"}`},
+	}
+
+	for i, tt := range tests {
+		log := &logger.Logger{Level: 4, Format: logger.JSONFormat, Sanitize: tt.mode}
+		log.SetOutput(&bytes.Buffer{})
+		s := log.With(logger.F("user", "x\ny")).Info("a\nb \033[31mred\033[0m")
+		if !bytes.HasSuffix([]byte(s), []byte(tt.out)) {
+			t.Errorf("Test(%d) expected suffix: %v actual: %v", i, tt.out, s)
+		}
+	}
+}
+
+func TestNewLoggerSanitize(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out logger.SanitizeMode
+	}{
+		{"", logger.SanitizeAuto},
+		{"none", logger.SanitizeNone},
+		{"STRIP", logger.SanitizeStrip},
+		{"escape", logger.SanitizeEscape},
+	}
+
+	for i, tt := range tests {
+		os.Setenv("LOG_SANITIZE", tt.in)
+		actual := logger.NewLogger("test")
+		if tt.out != actual.Sanitize {
+			t.Errorf("Test(%v): expected %v, actual %v", i, tt.out, actual.Sanitize)
+		}
+	}
+	os.Unsetenv("LOG_SANITIZE")
+}
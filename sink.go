@@ -0,0 +1,47 @@
+package log
+
+// Sink receives every entry written by a logger in addition to its output
+type Sink interface {
+	Write(e *Entry) error
+	Close() error
+}
+
+// AddSink adds a sink to the logger. Child loggers created afterwards with
+// With share it.
+func (l *Logger) AddSink(s Sink) {
+	reportAsync(s, l)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks[:len(l.sinks):len(l.sinks)], s)
+}
+
+// Close writes pending sampling, rate limit and repeat summaries, flushes
+// and closes the sinks of the logger and returns the first error
+func (l *Logger) Close() error {
+	l = l.live()
+	if l.Sampler != nil {
+		for _, s := range l.Sampler.flush() {
+			l.emit(l.summary(s))
+		}
+	}
+	if l.RateLimiter != nil {
+		for _, s := range l.RateLimiter.flush() {
+			l.emit(l.summary(s))
+		}
+	}
+	if l.Dedup != nil {
+		l.Dedup.flush()
+	}
+
+	l.mu.Lock()
+	sinks := l.sinks
+	l.mu.Unlock()
+
+	var first error
+	for _, s := range sinks {
+		if err := s.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,115 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SinkError is passed to the error handler when writing an entry to the
+// output or a sink fails. Sink is nil for the logger output.
+type SinkError struct {
+	Sink  Sink
+	Entry *Entry
+	Err   error
+}
+
+func (e *SinkError) Error() string {
+	if e.Sink == nil {
+		return "log: write to output failed: " + e.Err.Error()
+	}
+	return fmt.Sprintf("log: write to sink %T failed: %v", e.Sink, e.Err)
+}
+
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
+
+// SinkStats counts failed writes of a logger and the loggers created from it
+// with With
+type SinkStats struct {
+	Errors         uint64
+	Fallbacks      uint64
+	FallbackErrors uint64
+}
+
+type sinkCounters struct {
+	errors         uint64
+	fallbacks      uint64
+	fallbackErrors uint64
+}
+
+// SinkStats returns the number of failed writes and fallback writes
+func (l *Logger) SinkStats() SinkStats {
+	l = l.live()
+	c := l.counters()
+	return SinkStats{
+		Errors:         atomic.LoadUint64(&c.errors),
+		Fallbacks:      atomic.LoadUint64(&c.fallbacks),
+		FallbackErrors: atomic.LoadUint64(&c.fallbackErrors),
+	}
+}
+
+func (l *Logger) counters() *sinkCounters {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.countersLocked()
+}
+
+func (l *Logger) countersLocked() *sinkCounters {
+	if l.sinkCounters == nil {
+		l.sinkCounters = &sinkCounters{}
+	}
+	return l.sinkCounters
+}
+
+// sinkError counts a failed write, sends the entry to the fallback sink and
+// reports the error
+func (l *Logger) sinkError(s Sink, e *Entry, err error) {
+	c := l.counters()
+	atomic.AddUint64(&c.errors, 1)
+	l.Metrics.sinkError()
+	if l.Fallback != nil && s != l.Fallback {
+		atomic.AddUint64(&c.fallbacks, 1)
+		if ferr := writeSink(l.Fallback, e); ferr != nil {
+			atomic.AddUint64(&c.fallbackErrors, 1)
+		}
+	}
+
+	handler := l.ErrorHandler
+	if handler == nil {
+		handler = stderrHandler
+	}
+	handler(&SinkError{Sink: s, Entry: e, Err: err})
+}
+
+// errorInterval is the minimum time between two messages of the default
+// error handler
+const errorInterval = time.Minute
+
+var stderrLimit struct {
+	mu         sync.Mutex
+	last       time.Time
+	suppressed int
+}
+
+// stderrHandler writes at most one error per errorInterval to stderr and
+// counts the rest
+func stderrHandler(err *SinkError) {
+	stderrLimit.mu.Lock()
+	defer stderrLimit.mu.Unlock()
+	now := time.Now()
+	if !stderrLimit.last.IsZero() && now.Sub(stderrLimit.last) < errorInterval {
+		stderrLimit.suppressed++
+		return
+	}
+	msg := err.Error()
+	if stderrLimit.suppressed > 0 {
+		msg += fmt.Sprintf(" (%d more errors suppressed)", stderrLimit.suppressed)
+	}
+	stderrLimit.last = now
+	stderrLimit.suppressed = 0
+	fmt.Fprintln(os.Stderr, msg)
+}
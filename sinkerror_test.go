@@ -0,0 +1,76 @@
+package log_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestSinkErrorHandler(t *testing.T) {
+	var errs []*logger.SinkError
+	fallback := &memSink{}
+	broken := &memSink{err: errors.New("connection refused")}
+
+	log := &logger.Logger{Level: 4, Fallback: fallback}
+	log.ErrorHandler = func(err *logger.SinkError) { errs = append(errs, err) }
+	log.SetOutput(failWriter{})
+	log.AddSink(broken)
+
+	child := log.With(logger.F("k", "v"))
+	child.Info("lost")
+
+	if len(errs) != 2 {
+		t.Fatalf("Test expected: 2 errors actual: %v", errs)
+	}
+	if errs[0].Sink != nil || errs[0].Error() != "log: write to output failed: disk full" {
+		t.Errorf("Test expected output error actual: %v", errs[0])
+	}
+	if errs[1].Sink != broken || errs[1].Entry.Message != "lost" || errs[1].Err.Error() != "connection refused" {
+		t.Errorf("Test expected sink error actual: %v", errs[1])
+	}
+	if got := fallback.messages(); len(got) != 2 || got[0] != "lost" {
+		t.Errorf("Test expected fallback entries actual: %v", got)
+	}
+
+	expected := logger.SinkStats{Errors: 2, Fallbacks: 2}
+	if s := log.SinkStats(); s != expected {
+		t.Errorf("Test expected: %+v actual: %+v", expected, s)
+	}
+}
+
+func TestSinkErrorFallbackFails(t *testing.T) {
+	log := &logger.Logger{Level: 4, Fallback: &memSink{err: errors.New("also broken")}}
+	log.ErrorHandler = func(*logger.SinkError) {}
+	log.SetOutput(failWriter{})
+	log.Info("lost")
+
+	expected := logger.SinkStats{Errors: 1, Fallbacks: 1, FallbackErrors: 1}
+	if s := log.SinkStats(); s != expected {
+		t.Errorf("Test expected: %+v actual: %+v", expected, s)
+	}
+}
+
+func TestSinkErrorDefaultHandler(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.ResetStderrLimit()
+	stderr := os.Stderr
+	os.Stderr = w
+	log := &logger.Logger{Level: 4}
+	log.SetOutput(failWriter{})
+	log.Info("one")
+	log.Info("two")
+	os.Stderr = stderr
+	w.Close()
+
+	out, _ := io.ReadAll(r)
+	if strings.Count(string(out), "\n") != 1 || !strings.Contains(string(out), "disk full") {
+		t.Errorf("Test expected one rate limited message actual: %q", out)
+	}
+}
@@ -0,0 +1,264 @@
+package log
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// Spool buffers encoded entries while a network sink cannot deliver them.
+// Push drops the oldest entries when the spool is full and returns how many
+// were dropped.
+type Spool interface {
+	Push(b []byte) (dropped int, err error)
+	Peek(n int) ([][]byte, error)
+	Pop(n int) error
+	Len() int
+}
+
+// MemorySpool is a spool holding up to Max entries in memory
+type MemorySpool struct {
+	Max int
+
+	mu    sync.Mutex
+	items [][]byte
+}
+
+// NewMemorySpool creates a memory spool for size entries
+func NewMemorySpool(size int) *MemorySpool {
+	return &MemorySpool{Max: size}
+}
+
+// Push adds b to the spool
+func (s *MemorySpool) Push(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, b)
+	dropped := 0
+	if s.Max > 0 && len(s.items) > s.Max {
+		dropped = len(s.items) - s.Max
+		s.items = append([][]byte(nil), s.items[dropped:]...)
+	}
+	return dropped, nil
+}
+
+// Peek returns up to n of the oldest entries
+func (s *MemorySpool) Peek(n int) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > len(s.items) {
+		n = len(s.items)
+	}
+	return append([][]byte(nil), s.items[:n]...), nil
+}
+
+// Pop removes the n oldest entries
+func (s *MemorySpool) Pop(n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > len(s.items) {
+		n = len(s.items)
+	}
+	s.items = s.items[n:]
+	return nil
+}
+
+// Len returns the number of spooled entries
+func (s *MemorySpool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// DiskSpool is a spool stored in a file so undelivered entries survive a
+// restart. The file starts with the offset of the oldest entry followed by
+// length prefixed entries. The file is rewritten once the removed entries
+// take more space than the remaining ones.
+type DiskSpool struct {
+	MaxBytes int64
+
+	mu    sync.Mutex
+	path  string
+	f     *os.File
+	head  int64
+	tail  int64
+	count int
+}
+
+const spoolHeader = 8
+
+// OpenDiskSpool opens or creates the spool file at path holding at most
+// maxBytes of entries
+func OpenDiskSpool(path string, maxBytes int64) (*DiskSpool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s := &DiskSpool{MaxBytes: maxBytes, path: path, f: f, head: spoolHeader, tail: spoolHeader}
+
+	var hdr [spoolHeader]byte
+	if _, err := f.ReadAt(hdr[:], 0); err == nil {
+		s.head = int64(binary.LittleEndian.Uint64(hdr[:]))
+	} else if err != io.EOF {
+		f.Close()
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if fi.Size() > spoolHeader {
+		s.tail = fi.Size()
+	}
+	if s.head < spoolHeader || s.head > s.tail {
+		f.Close()
+		return nil, errors.New("log: corrupt spool file " + path)
+	}
+	for off := s.head; off < s.tail; s.count++ {
+		n, err := s.size(off)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		off += 4 + n
+	}
+	return s, s.writeHead()
+}
+
+func (s *DiskSpool) size(off int64) (int64, error) {
+	var b [4]byte
+	if _, err := s.f.ReadAt(b[:], off); err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint32(b[:])), nil
+}
+
+func (s *DiskSpool) writeHead() error {
+	if s.head == s.tail {
+		s.head, s.tail = spoolHeader, spoolHeader
+		if err := s.f.Truncate(spoolHeader); err != nil {
+			return err
+		}
+	} else if s.head-spoolHeader > s.tail-s.head {
+		return s.compact()
+	}
+	var hdr [spoolHeader]byte
+	binary.LittleEndian.PutUint64(hdr[:], uint64(s.head))
+	_, err := s.f.WriteAt(hdr[:], 0)
+	return err
+}
+
+// compact copies the remaining entries to a new file which replaces the
+// spool file, a crash while copying leaves the old file intact
+func (s *DiskSpool) compact() error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	var hdr [spoolHeader]byte
+	binary.LittleEndian.PutUint64(hdr[:], spoolHeader)
+	_, err = f.Write(hdr[:])
+	if err == nil {
+		_, err = io.Copy(f, io.NewSectionReader(s.f, s.head, s.tail-s.head))
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	s.f.Close()
+	s.f = f
+	s.tail -= s.head - spoolHeader
+	s.head = spoolHeader
+	return nil
+}
+
+// Push appends b to the spool file
+func (s *DiskSpool) Push(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := make([]byte, 4+len(b))
+	binary.LittleEndian.PutUint32(rec, uint32(len(b)))
+	copy(rec[4:], b)
+	if _, err := s.f.WriteAt(rec, s.tail); err != nil {
+		return 0, err
+	}
+	s.tail += int64(len(rec))
+	s.count++
+
+	dropped := 0
+	for s.MaxBytes > 0 && s.tail-s.head > s.MaxBytes && s.count > 1 {
+		n, err := s.size(s.head)
+		if err != nil {
+			return dropped, err
+		}
+		s.head += 4 + n
+		s.count--
+		dropped++
+	}
+	if dropped > 0 {
+		return dropped, s.writeHead()
+	}
+	return 0, nil
+}
+
+// Peek returns up to n of the oldest entries
+func (s *DiskSpool) Peek(n int) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items [][]byte
+	for off := s.head; off < s.tail && len(items) < n; {
+		size, err := s.size(off)
+		if err != nil {
+			return items, err
+		}
+		b := make([]byte, size)
+		if _, err := s.f.ReadAt(b, off+4); err != nil {
+			return items, err
+		}
+		items = append(items, b)
+		off += 4 + size
+	}
+	return items, nil
+}
+
+// Pop removes the n oldest entries
+func (s *DiskSpool) Pop(n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ; n > 0 && s.head < s.tail; n-- {
+		size, err := s.size(s.head)
+		if err != nil {
+			return err
+		}
+		s.head += 4 + size
+		s.count--
+	}
+	return s.writeHead()
+}
+
+// Len returns the number of spooled entries
+func (s *DiskSpool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Close closes the spool file
+func (s *DiskSpool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
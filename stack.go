@@ -0,0 +1,56 @@
+package log
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// pkg is the import path of this package, used to trim its own frames
+var pkg = Caller{Function: runtime.FuncForPC(reflect.ValueOf(NewLogger).Pointer()).Name()}.Package()
+
+// stack returns the frames of the calling goroutine skip frames above its
+// caller, leaving out frames inside this package
+func stack(skip int) []Caller {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(skip+2, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+
+	var s []Caller
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		c := Caller{File: frame.File, Line: frame.Line, Function: frame.Function}
+		if c.Package() != pkg {
+			s = append(s, c)
+		}
+		if !more {
+			return s
+		}
+	}
+}
+
+func (l *Logger) wantStack(logLevel string) bool {
+	return l.Stack && level[logLevel] <= l.StackLevel
+}
+
+// formatStack renders frames like a goroutine trace, indented under the entry
+func formatStack(s []Caller) string {
+	var b strings.Builder
+	for _, c := range s {
+		b.WriteString("\n\t")
+		b.WriteString(c.Function)
+		b.WriteString("()\n\t\t")
+		b.WriteString(c.File)
+		b.WriteString(":")
+		b.WriteString(strconv.Itoa(c.Line))
+	}
+	return b.String()
+}
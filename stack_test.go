@@ -0,0 +1,73 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestStackText(t *testing.T) {
+	log := &logger.Logger{Level: 4, Stack: true, StackLevel: 2}
+	log.SetOutput(&bytes.Buffer{})
+
+	if s := log.Warn("warn"); strings.Contains(s, "\n") {
+		t.Errorf("Test expected no stack below StackLevel actual: %v", s)
+	}
+
+	s := log.Error("error")
+	lines := strings.Split(s, "\n")
+	if len(lines) < 3 {
+		t.Fatalf("Test expected stack actual: %v", s)
+	}
+	if !strings.HasSuffix(lines[0], "ERROR error") {
+		t.Errorf("Test expected message first actual: %v", lines[0])
+	}
+	if lines[1] != "\tgithub.com/casonadams/simple-logger_test.TestStackText()" {
+		t.Errorf("Test expected test function first actual: %v", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "\t\t") || !strings.Contains(lines[2], "stack_test.go:") {
+		t.Errorf("Test expected file line actual: %v", lines[2])
+	}
+	if strings.Contains(s, "simple-logger.(*Logger)") {
+		t.Errorf("Test expected logger frames trimmed actual: %v", s)
+	}
+	if s := log.With(logger.F("id", 1)).Error("error"); !strings.Contains(s, "\n\t") {
+		t.Errorf("Test expected child logger to keep stack option actual: %v", s)
+	}
+}
+
+func TestStackJSON(t *testing.T) {
+	log := &logger.Logger{Level: 4, Stack: true, StackLevel: 2, Format: logger.JSONFormat}
+	log.SetOutput(&bytes.Buffer{})
+	s := log.Error("error")
+
+	var m struct {
+		Stack []logger.Caller `json:"stack"`
+	}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatalf("invalid json %v: %v", err, s)
+	}
+	if len(m.Stack) == 0 || m.Stack[0].Function != "github.com/casonadams/simple-logger_test.TestStackJSON" {
+		t.Errorf("Test expected stack frames actual: %v", s)
+	}
+}
+
+func TestNewLoggerStack(t *testing.T) {
+	os.Setenv("LOG_STACK", "true")
+	os.Setenv("LOG_STACK_LEVEL", "warn")
+	log := logger.NewLogger("test")
+	if !log.Stack || log.StackLevel != 3 {
+		t.Errorf("Test expected stack at WARN actual: %v %v", log.Stack, log.StackLevel)
+	}
+
+	os.Unsetenv("LOG_STACK")
+	os.Unsetenv("LOG_STACK_LEVEL")
+	log = logger.NewLogger("test")
+	if log.Stack || log.StackLevel != 2 {
+		t.Errorf("Test expected no stack and ERROR default actual: %v %v", log.Stack, log.StackLevel)
+	}
+}
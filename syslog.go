@@ -0,0 +1,215 @@
+package log
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFormat selects the syslog message format
+type SyslogFormat int
+
+// Syslog formats
+const (
+	// RFC5424 is the current syslog protocol with structured data
+	RFC5424 SyslogFormat = iota
+	// RFC3164 is the legacy BSD syslog format
+	RFC3164
+)
+
+// syslogSeverity maps levels to syslog severities
+var syslogSeverity = map[string]int{
+	"DEBUG": 7,
+	"TRACE": 7,
+	"INFO":  6,
+	"WARN":  4,
+	"ERROR": 3,
+	"FATAL": 2,
+	"PANIC": 1,
+}
+
+// sdID is the structured data id used for fields, 32473 is the enterprise
+// number reserved for documentation and examples
+const sdID = "fields@32473"
+
+// SyslogSink is a sink that writes entries to a syslog daemon. Network is
+// one of udp, tcp, tls, unix or unixgram. An empty network connects to the
+// local daemon through /dev/log. TCP and TLS use octet counting framing.
+// AppName defaults to the logger name.
+type SyslogSink struct {
+	Network   string
+	Addr      string
+	Format    SyslogFormat
+	Facility  int
+	Hostname  string
+	AppName   string
+	TLSConfig *tls.Config
+	Timeout   time.Duration
+
+	mu      sync.Mutex
+	conn    net.Conn
+	connNet string
+}
+
+// NewSyslogSink creates an RFC 5424 sink with the user facility
+func NewSyslogSink(network, addr string) *SyslogSink {
+	hostname, _ := os.Hostname()
+	return &SyslogSink{
+		Network:  network,
+		Addr:     addr,
+		Facility: 1,
+		Hostname: hostname,
+		Timeout:  5 * time.Second,
+	}
+}
+
+// Write sends e to the syslog daemon, reconnecting once if the connection
+// failed
+func (s *SyslogSink) Write(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if err = s.connect(); err != nil {
+				continue
+			}
+		}
+		if _, err = s.conn.Write(s.frame(s.message(e))); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+// Close closes the connection
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *SyslogSink) connect() error {
+	d := net.Dialer{Timeout: s.Timeout}
+	switch s.Network {
+	case "":
+		for _, addr := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+			for _, network := range []string{"unixgram", "unix"} {
+				conn, err := d.Dial(network, addr)
+				if err == nil {
+					s.conn, s.connNet = conn, network
+					return nil
+				}
+			}
+		}
+		return errors.New("log: no local syslog daemon found")
+	case "tls":
+		conn, err := tls.DialWithDialer(&d, "tcp", s.Addr, s.TLSConfig)
+		if err != nil {
+			return err
+		}
+		s.conn, s.connNet = conn, s.Network
+		return nil
+	}
+	conn, err := d.Dial(s.Network, s.Addr)
+	if err != nil {
+		return err
+	}
+	s.conn, s.connNet = conn, s.Network
+	return nil
+}
+
+func (s *SyslogSink) frame(msg string) []byte {
+	switch s.connNet {
+	case "tcp", "tls":
+		return []byte(strconv.Itoa(len(msg)) + " " + msg)
+	case "unix":
+		return []byte(msg + "\n")
+	}
+	return []byte(msg)
+}
+
+func (s *SyslogSink) message(e *Entry) string {
+	severity, ok := syslogSeverity[e.Level]
+	if !ok {
+		severity = 6
+	}
+	pri := s.Facility*8 + severity
+
+	app := s.AppName
+	if app == "" {
+		app = e.Logger
+	}
+	if app == "" {
+		app = filepath.Base(os.Args[0])
+	}
+	msg := sanitize(e.Message, SanitizeStrip)
+
+	if s.Format == RFC3164 {
+		return fmt.Sprintf("<%d>%s %s %s[%d]: %s",
+			pri, e.Time.Format(time.Stamp), s.Hostname, app, os.Getpid(), msg)
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %d - %s %s",
+		pri, e.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		header(s.Hostname, 255), header(app, 48), os.Getpid(), structuredData(e.Fields), msg)
+}
+
+// header returns an RFC 5424 header field, printable ASCII of at most n
+// characters or - when empty
+func header(v string, n int) string {
+	v = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, v)
+	if len(v) > n {
+		v = v[:n]
+	}
+	if v == "" {
+		return "-"
+	}
+	return v
+}
+
+// structuredData renders fields as a single SD-ELEMENT
+func structuredData(fields []Field) string {
+	if len(fields) == 0 {
+		return "-"
+	}
+	var b strings.Builder
+	b.WriteString("[" + sdID)
+	for _, f := range fields {
+		name := strings.Map(func(r rune) rune {
+			if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+				return -1
+			}
+			return r
+		}, f.Key)
+		if len(name) > 32 {
+			name = name[:32]
+		}
+		if name == "" {
+			continue
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(sanitize(fmt.Sprint(f.Value), SanitizeStrip))
+		b.WriteString(" " + name + `="` + value + `"`)
+	}
+	b.WriteString("]")
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package log_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestSyslogUDP5424(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	s := logger.NewSyslogSink("udp", pc.LocalAddr().String())
+	s.Hostname = "host1"
+	log := newSinkLogger("billing", 4, s)
+	defer log.Close()
+	log.With(logger.F("user", `a"b]`), logger.F("bad key", 1)).Warn("disk\nfull")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid := strconv.Itoa(os.Getpid())
+	re := regexp.MustCompile(`^<12>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}\S+ host1 billing ` + pid + ` - \[fields@32473 user="a\\"b\\]" badkey="1"\] disk\\nfull$`)
+	if !re.Match(buf[:n]) {
+		t.Errorf("Test expected: %v actual: %s", re, buf[:n])
+	}
+}
+
+func TestSyslogTCPReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	lines := make(chan string, 2)
+	go func() {
+		for i := 0; i < 2; i++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			size, _ := r.ReadString(' ')
+			n, _ := strconv.Atoi(strings.TrimSpace(size))
+			msg := make([]byte, n)
+			io.ReadFull(r, msg)
+			lines <- size + string(msg)
+			conn.Close()
+		}
+	}()
+
+	s := logger.NewSyslogSink("tcp", ln.Addr().String())
+	s.Facility = 16
+	log := newSinkLogger("billing", 4, s)
+	defer log.Close()
+
+	log.Error("first")
+	first := <-lines
+	if !regexp.MustCompile(`^\d+ <131>1 .* billing \d+ - - first$`).MatchString(first) {
+		t.Errorf("unexpected message %q", first)
+	}
+
+	// the server closed the connection, writes fail until the sink redials
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		log.Error("second")
+		select {
+		case second := <-lines:
+			if !strings.HasSuffix(second, " - - second") {
+				t.Errorf("unexpected message %q", second)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Errorf("expected the sink to reconnect")
+}
+
+func TestSyslogUnix3164(t *testing.T) {
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "log")
+
+	pc, err := net.ListenPacket("unixgram", addr)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer pc.Close()
+
+	s := logger.NewSyslogSink("unixgram", addr)
+	s.Format = logger.RFC3164
+	s.Hostname = "host1"
+	s.AppName = "app"
+	log := newSinkLogger("billing", 4, s)
+	defer log.Close()
+	log.Info("hello")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(`^<14>\w{3} [ \d]\d \d{2}:\d{2}:\d{2} host1 app\[` + strconv.Itoa(os.Getpid()) + `\]: hello$`)
+	if !re.Match(buf[:n]) {
+		t.Errorf("Test expected: %v actual: %s", re, buf[:n])
+	}
+}
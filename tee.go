@@ -0,0 +1,219 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// WriterSink is a sink that writes entries at or above Level to a writer
+// with its own format and color. Together with a discarded logger output it
+// lets one logger write colored text to the console, JSON to a file and
+// errors to the network at different levels. The logger Level must be at
+// least as verbose as the most verbose sink.
+type WriterSink struct {
+	Level      int
+	Format     Format
+	Color      bool
+	ColorMode  ColorMode
+	Theme      *Theme
+	Date       bool
+	Function   bool
+	CallerPath PathMode
+	CallerFunc bool
+	Sanitize   SanitizeMode
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink writing entries at or above level to w. Color
+// is decided by ColorAuto against w.
+func NewWriterSink(w io.Writer, level int, format Format) *WriterSink {
+	return &WriterSink{
+		Level:     level,
+		Format:    format,
+		Color:     ColorAuto.enabled(w),
+		ColorMode: ColorAuto,
+		Theme:     DefaultTheme,
+		Date:      true,
+		Function:  true,
+		w:         w,
+	}
+}
+
+// SetColorMode sets the color mode and re-evaluates Color for the writer
+func (s *WriterSink) SetColorMode(m ColorMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ColorMode = m
+	s.Color = m.enabled(s.w)
+}
+
+// Write formats e and writes it when its level is enabled for the sink
+func (s *WriterSink) Write(e *Entry) error {
+	if s.Level < level[e.Level] {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := &Logger{
+		Date:       s.Date,
+		Color:      s.Color,
+		ColorMode:  s.ColorMode,
+		Theme:      s.Theme,
+		Function:   s.Function,
+		CallerPath: s.CallerPath,
+		CallerFunc: s.CallerFunc,
+		Format:     s.Format,
+		Sanitize:   s.Sanitize,
+	}
+	_, err := fmt.Fprintln(s.w, enc.format(e))
+	return err
+}
+
+// Close closes the writer when it is an io.Closer other than os.Stdout or
+// os.Stderr
+func (s *WriterSink) Close() error {
+	if s.w == os.Stdout || s.w == os.Stderr {
+		return nil
+	}
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+type levelSink struct {
+	Sink
+	level int
+}
+
+// LevelSink wraps s so that it only receives entries at or above lvl
+func LevelSink(s Sink, lvl int) Sink {
+	return &levelSink{Sink: s, level: lvl}
+}
+
+func (s *levelSink) Write(e *Entry) error {
+	if s.level < level[e.Level] {
+		return nil
+	}
+	return s.Sink.Write(e)
+}
+
+var errAsyncFull = errors.New("log: async sink buffer is full")
+
+// AsyncSink writes entries to a sink from a background goroutine so that a
+// slow or blocked sink does not hold up the logger or the other sinks.
+// Entries are dropped when the buffer is full. Errors of the wrapped sink
+// are reported to the logger the sink was first added to.
+type AsyncSink struct {
+	sink    Sink
+	entries chan *Entry
+	done    chan struct{}
+	dropped uint64
+
+	mu      sync.RWMutex
+	closed  bool
+	onError func(s Sink, e *Entry, err error)
+}
+
+// NewAsyncSink wraps s with a buffer of size entries
+func NewAsyncSink(s Sink, size int) *AsyncSink {
+	a := &AsyncSink{
+		sink:    s,
+		entries: make(chan *Entry, size),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Dropped returns the number of entries dropped because the buffer was full
+func (a *AsyncSink) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Write queues e without blocking
+func (a *AsyncSink) Write(e *Entry) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return errClosed
+	}
+	select {
+	case a.entries <- e:
+		return nil
+	default:
+		atomic.AddUint64(&a.dropped, 1)
+		return errAsyncFull
+	}
+}
+
+// Close writes the queued entries and closes the wrapped sink
+func (a *AsyncSink) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.entries)
+	a.mu.Unlock()
+	<-a.done
+	return a.sink.Close()
+}
+
+// reportTo passes the errors of the wrapped sink to l unless they are
+// already reported to another logger
+func (a *AsyncSink) reportTo(l *Logger) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.onError == nil {
+		a.onError = l.sinkError
+	}
+}
+
+// reportAsync makes an AsyncSink in s, possibly wrapped by LevelSink, report
+// its errors to l
+func reportAsync(s Sink, l *Logger) {
+	for {
+		switch v := s.(type) {
+		case *levelSink:
+			s = v.Sink
+		case *AsyncSink:
+			v.reportTo(l)
+			return
+		default:
+			return
+		}
+	}
+}
+
+func (a *AsyncSink) run() {
+	defer close(a.done)
+	for e := range a.entries {
+		if err := writeSink(a.sink, e); err != nil {
+			a.mu.RLock()
+			onError := a.onError
+			a.mu.RUnlock()
+			if onError != nil {
+				onError(a.sink, e, err)
+			}
+		}
+	}
+}
+
+// writeSink writes e to s, turning a panic in the sink into an error so one
+// broken sink does not stop the others
+func writeSink(s Sink, e *Entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("log: sink panicked: %v", r)
+		}
+	}()
+	return s.Write(e)
+}
@@ -0,0 +1,181 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+type memSink struct {
+	mu      sync.Mutex
+	entries []*logger.Entry
+	err     error
+	closed  bool
+}
+
+func (s *memSink) Write(e *logger.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+	return s.err
+}
+
+func (s *memSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *memSink) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var m []string
+	for _, e := range s.entries {
+		m = append(m, e.Message)
+	}
+	return m
+}
+
+type panicSink struct{}
+
+func (panicSink) Write(e *logger.Entry) error { panic("broken") }
+func (panicSink) Close() error                { return nil }
+
+func TestFanOut(t *testing.T) {
+	var console, file bytes.Buffer
+	network := &memSink{}
+
+	log := &logger.Logger{Level: 6, ErrorHandler: func(*logger.SinkError) {}}
+	log.SetOutput(io.Discard)
+	text := logger.NewWriterSink(&console, 4, logger.TextFormat)
+	text.SetColorMode(logger.ColorAlways)
+	log.AddSink(text)
+	log.AddSink(panicSink{})
+	log.AddSink(logger.NewWriterSink(&file, 6, logger.JSONFormat))
+	log.AddSink(logger.LevelSink(network, 2))
+
+	log.Debug("debug")
+	log.Info("info")
+	log.Error("error")
+
+	if strings.Contains(console.String(), "debug") || !strings.Contains(console.String(), "\x1b[") {
+		t.Errorf("Test expected colored INFO and ERROR actual: %q", console.String())
+	}
+	if n := strings.Count(console.String(), "\n"); n != 2 {
+		t.Errorf("Test expected: 2 console lines actual: %d", n)
+	}
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Test expected: 3 file lines actual: %q", file.String())
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &m); err != nil || m["msg"] != "debug" {
+		t.Errorf("Test expected JSON debug actual: %s", lines[0])
+	}
+
+	if got := network.messages(); len(got) != 1 || got[0] != "error" {
+		t.Errorf("Test expected: [error] actual: %v", got)
+	}
+}
+
+type blockSink struct {
+	memSink
+	release chan struct{}
+}
+
+func (s *blockSink) Write(e *logger.Entry) error {
+	<-s.release
+	return s.memSink.Write(e)
+}
+
+func TestAsyncSink(t *testing.T) {
+	blocked := &blockSink{release: make(chan struct{})}
+	async := logger.NewAsyncSink(blocked, 1)
+	other := &memSink{}
+
+	log := &logger.Logger{Level: 4, ErrorHandler: func(*logger.SinkError) {}}
+	log.SetOutput(io.Discard)
+	log.AddSink(async)
+	log.AddSink(other)
+
+	log.Info("one")
+	log.Info("two")
+	log.Info("three")
+	if got := other.messages(); len(got) != 3 {
+		t.Errorf("Test expected: 3 entries actual: %v", got)
+	}
+
+	close(blocked.release)
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if async.Dropped() == 0 {
+		t.Errorf("Test expected dropped entries")
+	}
+	got := blocked.messages()
+	if len(got) == 0 || got[0] != "one" || len(got)+int(async.Dropped()) != 3 {
+		t.Errorf("Test expected one delivered first actual: %v dropped: %d", got, async.Dropped())
+	}
+	if !blocked.closed {
+		t.Errorf("Test expected wrapped sink closed")
+	}
+	if err := async.Write(&logger.Entry{}); err == nil {
+		t.Errorf("Test expected error after close")
+	}
+}
+
+func TestWriterSinkError(t *testing.T) {
+	s := logger.NewWriterSink(failWriter{}, 4, logger.TextFormat)
+	if err := s.Write(&logger.Entry{Level: "INFO"}); err == nil {
+		t.Errorf("Test expected write error")
+	}
+	if err := s.Write(&logger.Entry{Level: "DEBUG"}); err != nil {
+		t.Errorf("Test expected filtered entry actual: %v", err)
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestAsyncSinkErrors(t *testing.T) {
+	failing := &memSink{err: errors.New("disk full")}
+	var mu sync.Mutex
+	var errs []*logger.SinkError
+	log := &logger.Logger{Level: 4, ErrorHandler: func(err *logger.SinkError) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}}
+	log.SetOutput(io.Discard)
+	log.AddSink(logger.LevelSink(logger.NewAsyncSink(failing, 10), 4))
+
+	log.Info("one")
+	log.Info("two")
+	log.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 2 || errs[0].Sink != failing || errs[0].Entry.Message != "one" {
+		t.Errorf("Test expected: 2 errors of the wrapped sink actual: %v", errs)
+	}
+	if s := log.SinkStats(); s.Errors != 2 {
+		t.Errorf("Test expected: 2 actual: %+v", s)
+	}
+}
+
+// newSinkLogger creates a logger writing only to sink
+func newSinkLogger(name string, level int, sink logger.Sink) *logger.Logger {
+	log := &logger.Logger{Name: name, Level: level}
+	log.SetOutput(&bytes.Buffer{})
+	log.AddSink(sink)
+	return log
+}
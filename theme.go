@@ -0,0 +1,153 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color is an ANSI foreground color. The basic colors below, Color256 and RGB
+// all implement it.
+type Color interface {
+	sgr() string
+}
+
+type color int
+
+// Colors
+const (
+	DMAGENTA color = 35
+	GRAY     color = 90
+	RED      color = 91
+	YELLOW   color = 93
+	BLUE     color = 94
+	MAGENTA  color = 95
+	CYAN     color = 96
+)
+
+func (c color) sgr() string {
+	return strconv.Itoa(int(c))
+}
+
+type color256 uint8
+
+// Color256 returns a color from the 256 color palette
+func Color256(n uint8) Color {
+	return color256(n)
+}
+
+func (c color256) sgr() string {
+	return fmt.Sprintf("38;5;%d", c)
+}
+
+type rgb [3]uint8
+
+// RGB returns a 24-bit truecolor color
+func RGB(r, g, b uint8) Color {
+	return rgb{r, g, b}
+}
+
+func (c rgb) sgr() string {
+	return fmt.Sprintf("38;2;%d;%d;%d", c[0], c[1], c[2])
+}
+
+// Style describes how a part of a log line is rendered
+type Style struct {
+	Color     Color
+	Bold      bool
+	Underline bool
+}
+
+func (s Style) render(m string) string {
+	var params []string
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Underline {
+		params = append(params, "4")
+	}
+	if s.Color != nil {
+		params = append(params, s.Color.sgr())
+	}
+	if len(params) == 0 {
+		return m
+	}
+	return "\033[" + strings.Join(params, ";") + "m" + m + "\033[0m"
+}
+
+// Theme holds the styles used when color is enabled
+type Theme struct {
+	Levels map[string]Style
+	Time   Style
+	Caller Style
+	Key    Style
+}
+
+// Built in themes
+var (
+	// DefaultTheme colors only the level using the basic colors
+	DefaultTheme = &Theme{
+		Levels: map[string]Style{
+			"DEBUG": {Color: GRAY},
+			"TRACE": {Color: CYAN},
+			"INFO":  {Color: BLUE},
+			"WARN":  {Color: YELLOW},
+			"ERROR": {Color: RED},
+			"FATAL": {Color: MAGENTA},
+			"PANIC": {Color: DMAGENTA},
+		},
+	}
+
+	// DarkTheme is meant for terminals with a dark background
+	DarkTheme = &Theme{
+		Levels: map[string]Style{
+			"DEBUG": {Color: Color256(245)},
+			"TRACE": {Color: Color256(51)},
+			"INFO":  {Color: Color256(39)},
+			"WARN":  {Color: Color256(214), Bold: true},
+			"ERROR": {Color: Color256(196), Bold: true},
+			"FATAL": {Color: RGB(255, 95, 255), Bold: true, Underline: true},
+			"PANIC": {Color: RGB(255, 0, 135), Bold: true, Underline: true},
+		},
+		Time:   Style{Color: Color256(242)},
+		Caller: Style{Color: Color256(244)},
+		Key:    Style{Color: Color256(109)},
+	}
+
+	// LightTheme is meant for terminals with a light background
+	LightTheme = &Theme{
+		Levels: map[string]Style{
+			"DEBUG": {Color: Color256(240)},
+			"TRACE": {Color: Color256(30)},
+			"INFO":  {Color: Color256(25)},
+			"WARN":  {Color: Color256(130), Bold: true},
+			"ERROR": {Color: Color256(160), Bold: true},
+			"FATAL": {Color: RGB(135, 0, 135), Bold: true, Underline: true},
+			"PANIC": {Color: RGB(135, 0, 95), Bold: true, Underline: true},
+		},
+		Time:   Style{Color: Color256(246)},
+		Caller: Style{Color: Color256(243)},
+		Key:    Style{Color: Color256(24)},
+	}
+)
+
+// Themes maps LOG_THEME values to themes
+var Themes = map[string]*Theme{
+	"default": DefaultTheme,
+	"dark":    DarkTheme,
+	"light":   LightTheme,
+}
+
+func (l *Logger) theme() *Theme {
+	if l.Theme == nil {
+		return DefaultTheme
+	}
+	return l.Theme
+}
+
+func (l *Logger) paint(m string, s Style) string {
+	if l.colored() {
+		return s.render(m)
+	}
+	return m
+}
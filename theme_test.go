@@ -0,0 +1,82 @@
+package log_test
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+func TestNewLoggerTheme(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out *logger.Theme
+	}{
+		{"", logger.DefaultTheme},
+		{"default", logger.DefaultTheme},
+		{"dark", logger.DarkTheme},
+		{"DARK", logger.DarkTheme},
+		{"light", logger.LightTheme},
+		{"unknown", logger.DefaultTheme},
+	}
+
+	for i, tt := range tests {
+		os.Setenv("LOG_THEME", tt.in)
+		actual := logger.NewLogger("test")
+		if tt.out != actual.Theme {
+			t.Errorf("Test(%v): expected %v, actual %v", i, tt.out, actual.Theme)
+		}
+	}
+	os.Unsetenv("LOG_THEME")
+}
+
+func TestThemeStyles(t *testing.T) {
+	var tests = []struct {
+		style logger.Style
+		regex string
+	}{
+		{logger.Style{}, `^\S+ INFO info$`},
+		{logger.Style{Color: logger.RED}, `^\S+ \033\[91mINFO\033\[0m info$`},
+		{logger.Style{Color: logger.Color256(208)}, `^\S+ \033\[38;5;208mINFO\033\[0m info$`},
+		{logger.Style{Color: logger.RGB(1, 2, 3)}, `^\S+ \033\[38;2;1;2;3mINFO\033\[0m info$`},
+		{logger.Style{Bold: true}, `^\S+ \033\[1mINFO\033\[0m info$`},
+		{logger.Style{Color: logger.BLUE, Bold: true, Underline: true}, `^\S+ \033\[1;4;94mINFO\033\[0m info$`},
+	}
+
+	for i, tt := range tests {
+		log := &logger.Logger{
+			Level:     4,
+			ColorMode: logger.ColorAlways,
+			Theme:     &logger.Theme{Levels: map[string]logger.Style{"INFO": tt.style}},
+		}
+		log.SetOutput(&bytes.Buffer{})
+		s := log.Info("info")
+		if !regexp.MustCompile(tt.regex).MatchString(s) {
+			t.Errorf("Test(%d) expected: %v actual: %q", i, tt.regex, s)
+		}
+	}
+}
+
+func TestThemeParts(t *testing.T) {
+	log := &logger.Logger{
+		Level:     4,
+		Function:  true,
+		ColorMode: logger.ColorAlways,
+		Theme:     logger.DarkTheme,
+	}
+	log.SetOutput(&bytes.Buffer{})
+	s := log.With(logger.F("id", 1)).Info("info")
+	re := regexp.MustCompile(`^\033\[38;5;242m\d{2}:\d{2}:\d{2}.\d{3}\033\[0m \033\[38;5;39mINFO\033\[0m \033\[38;5;244m\[\w+.\w+:\d+\]\033\[0m info \033\[38;5;109mid\033\[0m=1$`)
+	if !re.MatchString(s) {
+		t.Errorf("Test expected: %v actual: %q", re, s)
+	}
+
+	log.SetColorMode(logger.ColorNever)
+	s = log.With(logger.F("id", 1)).Info("info")
+	re = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}.\d{3} INFO \[\w+.\w+:\d+\] info id=1$`)
+	if !re.MatchString(s) {
+		t.Errorf("Test expected: %v actual: %q", re, s)
+	}
+}
@@ -0,0 +1,30 @@
+package log
+
+import (
+	"context"
+	"encoding/hex"
+)
+
+// SpanContextFunc returns the W3C trace and span id of the span active in
+// ctx. ok is false when there is no valid span. With OpenTelemetry:
+//
+//	func(ctx context.Context) ([16]byte, [8]byte, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.TraceID(), sc.SpanID(), sc.IsValid()
+//	}
+type SpanContextFunc func(ctx context.Context) (traceID [16]byte, spanID [8]byte, ok bool)
+
+// TraceExtractor returns an extractor that adds trace_id and span_id fields
+// for the span active in the context
+func TraceExtractor(span SpanContextFunc) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		traceID, spanID, ok := span(ctx)
+		if !ok || traceID == ([16]byte{}) || spanID == ([8]byte{}) {
+			return nil
+		}
+		return []Field{
+			{Key: "trace_id", Value: hex.EncodeToString(traceID[:])},
+			{Key: "span_id", Value: hex.EncodeToString(spanID[:])},
+		}
+	}
+}
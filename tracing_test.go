@@ -0,0 +1,59 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	logger "github.com/casonadams/simple-logger"
+)
+
+type fakeSpan struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+type spanKey struct{}
+
+func fakeSpanContext(ctx context.Context) ([16]byte, [8]byte, bool) {
+	s, ok := ctx.Value(spanKey{}).(fakeSpan)
+	return s.traceID, s.spanID, ok
+}
+
+func TestTraceExtractor(t *testing.T) {
+	span := fakeSpan{
+		traceID: [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		spanID:  [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	}
+	ctx := context.WithValue(context.Background(), spanKey{}, span)
+
+	log := &logger.Logger{Level: 4}
+	log.SetOutput(&bytes.Buffer{})
+	log.Extractors = []logger.ContextExtractor{logger.TraceExtractor(fakeSpanContext)}
+
+	s := log.InfoCtx(ctx, "info")
+	expected := "INFO info trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7"
+	if !strings.HasSuffix(s, expected) {
+		t.Errorf("Test expected: %v actual: %v", expected, s)
+	}
+
+	if s := log.InfoCtx(context.Background(), "info"); !strings.HasSuffix(s, "INFO info") {
+		t.Errorf("Test expected no trace ids actual: %v", s)
+	}
+
+	invalid := context.WithValue(context.Background(), spanKey{}, fakeSpan{})
+	if s := log.InfoCtx(invalid, "info"); !strings.HasSuffix(s, "INFO info") {
+		t.Errorf("Test expected no trace ids for invalid span actual: %v", s)
+	}
+
+	log.Format = logger.JSONFormat
+	var m map[string]string
+	if err := json.Unmarshal([]byte(log.InfoCtx(ctx, "info")), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || m["span_id"] != "00f067aa0ba902b7" {
+		t.Errorf("Test expected trace keys actual: %v", m)
+	}
+}